@@ -0,0 +1,31 @@
+// cmd/server/config.go
+package main
+
+import (
+	"errors"
+	"io"
+
+	"github.com/your-username/tmf632-service/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `usage: server config <command>
+
+commands:
+  print  print the effective configuration as YAML, secrets redacted`
+
+// runConfig executes the config subcommand given its arguments. cfg has
+// already been loaded and validated, so invalid settings fail before this
+// runs.
+func runConfig(w io.Writer, cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
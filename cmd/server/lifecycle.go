@@ -0,0 +1,66 @@
+// cmd/server/lifecycle.go
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lifecycle stops the server's components in the reverse order they were
+// registered, so each one outlives everything that depends on it.
+type lifecycle struct {
+	logger     *zap.SugaredLogger
+	components []component
+}
+
+type component struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// onStop registers stop to run at shutdown.
+func (l *lifecycle) onStop(name string, stop func(ctx context.Context) error) {
+	l.components = append(l.components, component{name: name, stop: stop})
+}
+
+// stopAll stops every component, newest first. A failing component is
+// logged and the rest are still stopped.
+func (l *lifecycle) stopAll(ctx context.Context) {
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		start := time.Now()
+		if err := c.stop(ctx); err != nil {
+			l.logger.Errorw("Failed to stop component",
+				"component", c.name,
+				"error", err,
+				"duration", time.Since(start),
+			)
+			continue
+		}
+		l.logger.Infow("Component stopped",
+			"component", c.name,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// bounded adapts a blocking Stop method to give up once ctx expires, so a
+// stuck worker cannot keep the components after it from stopping.
+func bounded(stop func()) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			stop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -2,15 +2,40 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/auth"
 	"github.com/your-username/tmf632-service/internal/config"
 	"github.com/your-username/tmf632-service/internal/database"
+	"github.com/your-username/tmf632-service/internal/events"
 	"github.com/your-username/tmf632-service/internal/handlers"
+	"github.com/your-username/tmf632-service/internal/health"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/logger"
+	"github.com/your-username/tmf632-service/internal/masking"
+	"github.com/your-username/tmf632-service/internal/metrics"
+	"github.com/your-username/tmf632-service/internal/migrate"
+	"github.com/your-username/tmf632-service/internal/repository"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"github.com/your-username/tmf632-service/internal/tracing"
+	"github.com/your-username/tmf632-service/internal/validation"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
 )
 
+const serviceName = "tmf632-service"
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -18,31 +43,315 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize database
-	db, err := database.Initialize(cfg)
+	// server config <command> inspects the configuration and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Stdout, cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Config command failed: %v", err)
+		}
+		return
+	}
+
+	// Initialize logger
+	zapLogger, err := logger.NewLogger(cfg.Log.Level, cfg.Log.Format)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	defer zapLogger.Sync()
+	sugar := zapLogger.Sugar()
+
+	// server migrate <command> manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		_, migrator := openDatabase(cfg.Database, sugar)
+		if err := runMigrate(context.Background(), migrator, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Components register how to stop as they start; shutdown stops them
+	// in reverse, so the database is closed and spans are flushed last
+	lc := &lifecycle{logger: sugar}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, serviceName)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lc.onStop("tracing", shutdownTracing)
 
 	// Initialize Echo
 	e := echo.New()
+	e.HTTPErrorHandler = apierror.NewHTTPErrorHandler(sugar)
+	e.Validator = validation.NewValidator()
+
+	for _, server := range []*http.Server{e.Server, e.TLSServer} {
+		server.ReadTimeout = cfg.Server.ReadTimeout
+		server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+		server.WriteTimeout = cfg.Server.WriteTimeout
+		server.IdleTimeout = cfg.Server.IdleTimeout
+	}
+
+	// can returns the middleware that authorizes perm for a route; without
+	// authentication every caller may do everything and see every field
+	var apiMiddleware []echo.MiddlewareFunc
+	var fields masking.Policy
+	can := func(auth.Permission) []echo.MiddlewareFunc { return nil }
+	if cfg.Auth.Enabled {
+		apiMiddleware = append(apiMiddleware, auth.Middleware(newAuthenticator(cfg.Auth, sugar), sugar))
+		policy := auth.DefaultPolicy()
+		if cfg.Auth.PolicyFile != "" {
+			if policy, err = auth.LoadPolicy(cfg.Auth.PolicyFile); err != nil {
+				log.Fatalf("Failed to load authorization policy: %v", err)
+			}
+		}
+		fields = policy.Fields
+		can = func(perm auth.Permission) []echo.MiddlewareFunc {
+			return []echo.MiddlewareFunc{auth.Require(policy, perm, sugar)}
+		}
+	}
+	// The tenant is resolved after authentication, so it can come from the
+	// caller's token
+	apiMiddleware = append(apiMiddleware, auth.Tenant(cfg.Tenancy))
 
-	// Middleware
+	// Middleware; tracing and metrics sit outside Recover so panics count
+	// as 500s
+	m := metrics.New()
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
+	e.Use(tracing.Middleware("/metrics", "/health/live", "/health/ready", "/health/startup"))
+	e.Use(m.Middleware("/metrics"))
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{AllowOrigins: cfg.CORS.AllowOrigins}))
+	}
+	if cfg.RateLimit.Enabled {
+		e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:  rate.Limit(cfg.RateLimit.RequestsPerSecond),
+			Burst: cfg.RateLimit.Burst,
+		})))
+	}
+
+	probes := health.New(serviceName, cfg.Health.Timeout)
+
+	var repo repository.Repository
+	switch cfg.Repository {
+	case repository.KindMemory:
+		sugar.Warn("Using the in-memory repository; all data is lost on restart")
+
+		// Without an outbox, events are delivered to hub subscribers
+		// straight from memory
+		hub := newHubSink(nil, cfg.Events, m, fields, sugar)
+		relay := events.NewRelay(cfg.Events.QueueSize, sugar, hub)
+		relay.Start(context.Background())
+		lc.onStop("event relay", bounded(relay.Stop))
+		probes.Register(health.EventBacklog(relay, int64(cfg.Health.BacklogWarn)))
+		m.RegisterBacklog(relay)
+
+		memory := repository.NewMemory(relay.Enqueue)
+		hub.Subscriptions = memory
+		repo = memory
+
+	default:
+		db, migrator := openDatabase(cfg.Database, sugar)
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("Failed to access database pool: %v", err)
+		}
+		lc.onStop("database", func(context.Context) error { return sqlDB.Close() })
+		probes.Register(health.Database(sqlDB), health.Migrations(migrator))
+		m.RegisterDB(sqlDB, cfg.Database.Driver)
+		for _, plugin := range []gorm.Plugin{m.GormPlugin(), tracing.GormPlugin()} {
+			if err := db.Use(plugin); err != nil {
+				log.Fatalf("Failed to instrument database: %v", err)
+			}
+		}
+		// Statements on tenant data only ever see the request's tenant,
+		// enforced by row-level security as well on Postgres
+		if err := db.Use(tenant.GormPlugin(cfg.Database.Driver == migrate.Postgres)); err != nil {
+			log.Fatalf("Failed to scope database to tenants: %v", err)
+		}
+
+		if cfg.Database.AutoMigrate {
+			if err := migrator.Up(context.Background()); err != nil {
+				log.Fatalf("Failed to migrate database: %v", err)
+			}
+		}
+
+		// Party events are written to the outbox with each change and
+		// delivered to hub subscribers in the background
+		outbox := events.NewOutbox()
+		gormRepo := repository.NewGorm(db, outbox)
+		dispatcher := events.NewDispatcher(db, outbox, sugar, events.DispatcherOptions{
+			PollInterval: cfg.Events.PollInterval,
+			BatchSize:    cfg.Events.BatchSize,
+			MaxAttempts:  cfg.Events.MaxAttempts,
+			RetryBackoff: cfg.Events.RetryBackoff,
+			Lease:        cfg.Events.Lease,
+		}, newHubSink(gormRepo, cfg.Events, m, fields, sugar))
+		dispatcher.Start(context.Background())
+		lc.onStop("event dispatcher", bounded(dispatcher.Stop))
+		probes.Register(health.EventBacklog(dispatcher, int64(cfg.Health.BacklogWarn)))
+		m.RegisterBacklog(dispatcher)
+
+		// Expired idempotency keys are purged in the background
+		janitor := idempotency.NewJanitor(db, sugar, time.Hour)
+		janitor.Start(context.Background())
+		lc.onStop("idempotency janitor", bounded(janitor.Stop))
+
+		repo = gormRepo
+	}
+
+	m.RegisterStats(repo)
 
 	// Initialize handlers
-	h := handlers.NewHandler(db, cfg)
+	h := handlers.NewHandler(repo, cfg, sugar)
+	h.Masking = fields
 
 	// Routes
-	api := e.Group("/tmf-api/partyManagement/v4")
-	api.POST("/individual", h.CreateIndividual)
-	api.GET("/individual/:id", h.GetIndividual)
-	api.PUT("/individual/:id", h.UpdateIndividual)
-	api.DELETE("/individual/:id", h.DeleteIndividual)
-	api.GET("/individual", h.ListIndividuals)
-
-	// Start server
-	e.Logger.Fatal(e.Start(":" + cfg.ServerPort))
+	e.GET("/health/live", probes.Live)
+	e.GET("/health/ready", probes.Ready)
+	e.GET("/health/startup", probes.Startup)
+	e.GET("/metrics", m.Handler())
+
+	api := e.Group(cfg.API.BasePath, apiMiddleware...)
+	api.POST("/individual", h.CreateIndividual, can(auth.PartyWrite)...)
+	api.GET("/individual/:id", h.GetIndividual, can(auth.PartyRead)...)
+	api.PUT("/individual/:id", h.UpdateIndividual, can(auth.PartyWrite)...)
+	api.PATCH("/individual/:id", h.PatchIndividual, can(auth.PartyWrite)...)
+	api.DELETE("/individual/:id", h.DeleteIndividual, can(auth.PartyDelete)...)
+	api.GET("/individual", h.ListIndividuals, can(auth.PartyRead)...)
+	api.GET("/individual/:id/auditLog", h.GetIndividualAuditLog, can(auth.PartyAudit)...)
+
+	api.POST("/organization", h.CreateOrganization, can(auth.PartyWrite)...)
+	api.GET("/organization/:id", h.GetOrganization, can(auth.PartyRead)...)
+	api.PUT("/organization/:id", h.UpdateOrganization, can(auth.PartyWrite)...)
+	api.DELETE("/organization/:id", h.DeleteOrganization, can(auth.PartyDelete)...)
+	api.GET("/organization", h.ListOrganizations, can(auth.PartyRead)...)
+	api.GET("/organization/:id/auditLog", h.GetOrganizationAuditLog, can(auth.PartyAudit)...)
+
+	// Listeners receive the parties in their events, so registering one
+	// also needs party:read
+	api.POST("/hub", h.RegisterListener, append(can(auth.PartyRead), can(auth.HubWrite)...)...)
+	api.DELETE("/hub/:id", h.UnregisterListener, can(auth.HubWrite)...)
+
+	// Start server; SIGINT and SIGTERM begin a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		address := ":" + cfg.Server.Port
+		if cfg.Server.TLS.Enabled() {
+			serveErr <- e.StartTLS(address, cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+			return
+		}
+		serveErr <- e.Start(address)
+	}()
+	lc.onStop("http server", e.Shutdown)
+	probes.Started()
+
+	failed := false
+	select {
+	case <-ctx.Done():
+		// A second signal kills the process without waiting for the drain
+		stop()
+		sugar.Infow("Shutdown signal received, no longer ready",
+			"drain_delay", cfg.Server.DrainDelay,
+			"shutdown_timeout", cfg.Server.ShutdownTimeout,
+		)
+		probes.Drain()
+		time.Sleep(cfg.Server.DrainDelay)
+	case err := <-serveErr:
+		sugar.Errorw("Server stopped unexpectedly", "error", err)
+		failed = true
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	lc.stopAll(shutdownCtx)
+	if !failed {
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			sugar.Errorw("Server stopped unexpectedly", "error", err)
+			failed = true
+		}
+	}
+
+	sugar.Info("Shutdown complete")
+	if failed {
+		zapLogger.Sync()
+		os.Exit(1)
+	}
+}
+
+// newHubSink returns a HubSink with the configured delivery settings,
+// counting its deliveries in m and shaping events with fields.
+func newHubSink(subscriptions events.Subscriptions, cfg config.Events, m *metrics.Metrics, fields masking.Policy, logger *zap.SugaredLogger) *events.HubSink {
+	hub := events.NewHubSink(subscriptions, logger)
+	hub.Observe = m.ObserveDelivery
+	hub.Masking = fields
+	hub.Client.Timeout = cfg.DeliveryTimeout
+	// config.Validate has checked the networks
+	guard, _ := events.NewCallbackGuard(cfg.CallbackNetworks)
+	hub.Client.Transport = guard.Transport()
+	hub.MaxAttempts = cfg.DeliveryAttempts
+	hub.Backoff = cfg.DeliveryBackoff
+	return hub
+}
+
+// newAuthenticator builds the bearer token checks configured in cfg. The
+// signing keys are loaded up front so a wrong JWKS location shows at
+// startup, but a failure is not fatal: the keys are retried on demand.
+func newAuthenticator(cfg config.Auth, logger *zap.SugaredLogger) *auth.Authenticator {
+	a := &auth.Authenticator{}
+	if cfg.JWKSURL != "" || cfg.JWKSFile != "" {
+		keys := auth.NewKeySet(cfg.JWKSURL, cfg.JWKSFile, cfg.JWKSRefresh)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := keys.Refresh(ctx); err != nil {
+			logger.Warnw("Failed to load signing keys, retrying on first request", "error", err)
+		}
+		a.JWT = &auth.JWTVerifier{
+			Keys:      keys,
+			Issuer:    cfg.Issuer,
+			Audiences: cfg.Audiences,
+			Leeway:    time.Minute,
+		}
+	}
+	if cfg.IntrospectionURL != "" {
+		a.Introspector = auth.NewRemoteIntrospector(cfg.IntrospectionURL,
+			cfg.IntrospectionClientID, cfg.IntrospectionClientSecret, cfg.Audiences)
+	}
+	logger.Infow("Bearer authentication enabled",
+		"issuer", cfg.Issuer,
+		"jwt", a.JWT != nil,
+		"introspection", a.Introspector != nil,
+	)
+	return a
+}
+
+// openDatabase connects to the configured database and loads the embedded
+// schema migrations. An unreachable database is retried with backoff for up
+// to cfg.ConnectTimeout, so the server can start alongside its database.
+func openDatabase(cfg config.Database, logger *zap.SugaredLogger) (*gorm.DB, *migrate.Migrator) {
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	backoff := 500 * time.Millisecond
+	var db *gorm.DB
+	for {
+		var err error
+		db, err = database.Initialize(cfg)
+		if err == nil {
+			break
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		logger.Warnw("Database unavailable, retrying", "error", err, "retry_in", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 10*time.Second)
+	}
+
+	migrator, err := database.NewMigrator(db, logger)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	return db, migrator
 }
@@ -0,0 +1,66 @@
+// cmd/server/migrate.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/your-username/tmf632-service/internal/migrate"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [N]       revert the last N migrations (default 1)
+  version        print the current schema version
+  force VERSION  record VERSION as applied without running it`
+
+// runMigrate executes the migrate subcommand given its arguments.
+func runMigrate(ctx context.Context, m *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[1])
+			}
+			steps = n
+		}
+		if err := m.Down(ctx, steps); err != nil {
+			return err
+		}
+	case "version":
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("force expects a version number, got %q", args[1])
+		}
+		if err := m.Force(ctx, version); err != nil {
+			return err
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version %d (latest %d, dirty %t)\n", version, m.Latest(), dirty)
+	return nil
+}
@@ -0,0 +1,21 @@
+// db/migrations/migrations.go
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// files holds the versioned schema migrations, one directory per database
+// driver. Files follow the golang-migrate naming scheme,
+// NNNNNN_name.up.sql and NNNNNN_name.down.sql, so the migrate CLI can apply
+// them as well as the server binary. Both directories must describe the
+// same schema at every version.
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// FS returns the migrations for driver, "postgres" or "sqlite".
+func FS(driver string) (fs.FS, error) {
+	return fs.Sub(files, driver)
+}
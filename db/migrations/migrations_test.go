@@ -0,0 +1,32 @@
+// db/migrations/migrations_test.go
+package migrations
+
+import (
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/migrate"
+)
+
+func TestDriversMatch(t *testing.T) {
+	var loaded [2][]migrate.Migration
+	for i, driver := range []string{migrate.Postgres, migrate.SQLite} {
+		fsys, err := FS(driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded[i], err = migrate.Load(fsys); err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+	}
+
+	postgres, sqlite := loaded[0], loaded[1]
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("postgres has %d migrations, sqlite %d", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Errorf("postgres has %d_%s where sqlite has %d_%s",
+				postgres[i].Version, postgres[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+-- db/migrations/postgres/000001_create_individual_tables.down.sql
+DROP TABLE IF EXISTS party_characteristics;
+DROP TABLE IF EXISTS individual_identifications;
+DROP TABLE IF EXISTS external_references;
+DROP TABLE IF EXISTS contact_media;
+DROP TABLE IF EXISTS individuals;
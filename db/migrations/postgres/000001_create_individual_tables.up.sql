@@ -0,0 +1,89 @@
+-- db/migrations/postgres/000001_create_individual_tables.up.sql
+-- IF NOT EXISTS lets databases previously created by GORM AutoMigrate
+-- adopt the migration history without recreating their tables.
+
+CREATE TABLE IF NOT EXISTS individuals (
+    id VARCHAR(255) PRIMARY KEY,
+    href VARCHAR(255),
+    title VARCHAR(50),
+    given_name VARCHAR(255) NOT NULL,
+    family_name VARCHAR(255),
+    marital_status VARCHAR(1),
+    gender VARCHAR(1),
+    name_type VARCHAR(50),
+    nationality VARCHAR(3),
+    status VARCHAR(50),
+    creation_date TIMESTAMPTZ NOT NULL,
+    modification_date TIMESTAMPTZ NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS contact_media (
+    id VARCHAR(255) PRIMARY KEY,
+    individual_id VARCHAR(255) CONSTRAINT fk_individuals_contact_medium REFERENCES individuals(id),
+    type VARCHAR(50),
+    medium_type VARCHAR(50),
+    preferred BOOLEAN,
+    phone_number VARCHAR(50),
+    email_address VARCHAR(255),
+    street1 VARCHAR(255),
+    street2 VARCHAR(255),
+    city VARCHAR(255),
+    state_or_province VARCHAR(255),
+    country VARCHAR(255),
+    post_code VARCHAR(20),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS external_references (
+    id VARCHAR(255) PRIMARY KEY,
+    individual_id VARCHAR(255) CONSTRAINT fk_individuals_external_reference REFERENCES individuals(id),
+    name VARCHAR(255),
+    external_identifier_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS individual_identifications (
+    id VARCHAR(255) PRIMARY KEY,
+    individual_id VARCHAR(255) CONSTRAINT fk_individuals_individual_identification REFERENCES individuals(id),
+    identification_type VARCHAR(50),
+    identification_id VARCHAR(255),
+    valid_for_end TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS party_characteristics (
+    id VARCHAR(255) PRIMARY KEY,
+    individual_id VARCHAR(255) CONSTRAINT fk_individuals_party_characteristic REFERENCES individuals(id),
+    name VARCHAR(255),
+    value VARCHAR(255),
+    value_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_individuals_deleted_at ON individuals(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_individuals_given_name ON individuals(given_name);
+CREATE INDEX IF NOT EXISTS idx_individuals_family_name ON individuals(family_name);
+CREATE INDEX IF NOT EXISTS idx_contact_media_deleted_at ON contact_media(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_contact_media_individual_id ON contact_media(individual_id);
+CREATE INDEX IF NOT EXISTS idx_external_references_deleted_at ON external_references(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_external_references_individual_id ON external_references(individual_id);
+CREATE INDEX IF NOT EXISTS idx_individual_identifications_deleted_at ON individual_identifications(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_individual_identifications_individual_id ON individual_identifications(individual_id);
+CREATE INDEX IF NOT EXISTS idx_party_characteristics_deleted_at ON party_characteristics(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_party_characteristics_individual_id ON party_characteristics(individual_id);
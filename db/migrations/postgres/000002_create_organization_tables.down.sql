@@ -0,0 +1,7 @@
+-- db/migrations/postgres/000002_create_organization_tables.down.sql
+DROP TABLE IF EXISTS organization_parent_relationships;
+DROP TABLE IF EXISTS organization_child_relationships;
+DROP TABLE IF EXISTS organization_identifications;
+DROP TABLE IF EXISTS organization_external_references;
+DROP TABLE IF EXISTS organization_contact_media;
+DROP TABLE IF EXISTS organizations;
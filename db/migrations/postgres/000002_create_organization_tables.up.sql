@@ -0,0 +1,102 @@
+-- db/migrations/postgres/000002_create_organization_tables.up.sql
+CREATE TABLE IF NOT EXISTS organizations (
+    id VARCHAR(255) PRIMARY KEY,
+    href VARCHAR(255),
+    name VARCHAR(255) NOT NULL,
+    name_type VARCHAR(50),
+    trading_name VARCHAR(255),
+    organization_type VARCHAR(50),
+    is_legal_entity BOOLEAN,
+    is_head_office BOOLEAN,
+    status VARCHAR(50),
+    exists_during_start_date_time TIMESTAMPTZ,
+    exists_during_end_date_time TIMESTAMPTZ,
+    creation_date TIMESTAMPTZ NOT NULL,
+    modification_date TIMESTAMPTZ NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS organization_contact_media (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) CONSTRAINT fk_organizations_contact_medium REFERENCES organizations(id),
+    type VARCHAR(50),
+    medium_type VARCHAR(50),
+    preferred BOOLEAN,
+    phone_number VARCHAR(50),
+    email_address VARCHAR(255),
+    street1 VARCHAR(255),
+    street2 VARCHAR(255),
+    city VARCHAR(255),
+    state_or_province VARCHAR(255),
+    country VARCHAR(255),
+    post_code VARCHAR(20),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS organization_external_references (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) CONSTRAINT fk_organizations_external_reference REFERENCES organizations(id),
+    name VARCHAR(255),
+    external_identifier_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS organization_identifications (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) CONSTRAINT fk_organizations_organization_identification REFERENCES organizations(id),
+    identification_type VARCHAR(50),
+    identification_id VARCHAR(255),
+    issuing_authority VARCHAR(255),
+    issuing_date TIMESTAMPTZ,
+    valid_for_start_date_time TIMESTAMPTZ,
+    valid_for_end_date_time TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS organization_child_relationships (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) CONSTRAINT fk_organizations_organization_child_relationship REFERENCES organizations(id),
+    relationship_type VARCHAR(50),
+    child_id VARCHAR(255),
+    child_href VARCHAR(255),
+    child_name VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS organization_parent_relationships (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) CONSTRAINT fk_organizations_organization_parent_relationship REFERENCES organizations(id),
+    relationship_type VARCHAR(50),
+    parent_id VARCHAR(255),
+    parent_href VARCHAR(255),
+    parent_name VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organizations_name ON organizations(name);
+CREATE INDEX IF NOT EXISTS idx_organization_contact_media_deleted_at ON organization_contact_media(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_contact_media_organization_id ON organization_contact_media(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_external_references_deleted_at ON organization_external_references(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_external_references_organization_id ON organization_external_references(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_identifications_deleted_at ON organization_identifications(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_identifications_organization_id ON organization_identifications(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_child_relationships_deleted_at ON organization_child_relationships(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_child_relationships_organization_id ON organization_child_relationships(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_parent_relationships_deleted_at ON organization_parent_relationships(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_parent_relationships_organization_id ON organization_parent_relationships(organization_id);
@@ -0,0 +1,3 @@
+-- db/migrations/postgres/000003_create_event_tables.down.sql
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS event_subscriptions;
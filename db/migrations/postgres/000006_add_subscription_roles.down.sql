@@ -0,0 +1,2 @@
+-- db/migrations/postgres/000006_add_subscription_roles.down.sql
+ALTER TABLE event_subscriptions DROP COLUMN roles;
@@ -0,0 +1,2 @@
+-- db/migrations/postgres/000006_add_subscription_roles.up.sql
+ALTER TABLE event_subscriptions ADD COLUMN roles TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,120 @@
+-- db/migrations/postgres/000007_add_tenants.down.sql
+-- Fails, changing nothing, when two tenants use the same id.
+
+DROP POLICY IF EXISTS tenant_isolation ON individuals;
+ALTER TABLE individuals NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE individuals DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON contact_media;
+ALTER TABLE contact_media NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE contact_media DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON external_references;
+ALTER TABLE external_references NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE external_references DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON individual_identifications;
+ALTER TABLE individual_identifications NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE individual_identifications DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON party_characteristics;
+ALTER TABLE party_characteristics NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE party_characteristics DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organizations;
+ALTER TABLE organizations NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organizations DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organization_contact_media;
+ALTER TABLE organization_contact_media NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organization_contact_media DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organization_external_references;
+ALTER TABLE organization_external_references NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organization_external_references DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organization_identifications;
+ALTER TABLE organization_identifications NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organization_identifications DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organization_child_relationships;
+ALTER TABLE organization_child_relationships NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organization_child_relationships DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON organization_parent_relationships;
+ALTER TABLE organization_parent_relationships NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE organization_parent_relationships DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON event_subscriptions;
+ALTER TABLE event_subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE event_subscriptions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON outbox_events;
+ALTER TABLE outbox_events NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE outbox_events DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE contact_media DROP CONSTRAINT IF EXISTS fk_individuals_contact_medium;
+DROP INDEX IF EXISTS idx_contact_media_individual_id;
+CREATE INDEX idx_contact_media_individual_id ON contact_media(individual_id);
+ALTER TABLE external_references DROP CONSTRAINT IF EXISTS fk_individuals_external_reference;
+DROP INDEX IF EXISTS idx_external_references_individual_id;
+CREATE INDEX idx_external_references_individual_id ON external_references(individual_id);
+ALTER TABLE individual_identifications DROP CONSTRAINT IF EXISTS fk_individuals_individual_identification;
+DROP INDEX IF EXISTS idx_individual_identifications_individual_id;
+CREATE INDEX idx_individual_identifications_individual_id ON individual_identifications(individual_id);
+ALTER TABLE party_characteristics DROP CONSTRAINT IF EXISTS fk_individuals_party_characteristic;
+DROP INDEX IF EXISTS idx_party_characteristics_individual_id;
+CREATE INDEX idx_party_characteristics_individual_id ON party_characteristics(individual_id);
+ALTER TABLE organization_contact_media DROP CONSTRAINT IF EXISTS fk_organizations_contact_medium;
+DROP INDEX IF EXISTS idx_organization_contact_media_organization_id;
+CREATE INDEX idx_organization_contact_media_organization_id ON organization_contact_media(organization_id);
+ALTER TABLE organization_external_references DROP CONSTRAINT IF EXISTS fk_organizations_external_reference;
+DROP INDEX IF EXISTS idx_organization_external_references_organization_id;
+CREATE INDEX idx_organization_external_references_organization_id ON organization_external_references(organization_id);
+ALTER TABLE organization_identifications DROP CONSTRAINT IF EXISTS fk_organizations_organization_identification;
+DROP INDEX IF EXISTS idx_organization_identifications_organization_id;
+CREATE INDEX idx_organization_identifications_organization_id ON organization_identifications(organization_id);
+ALTER TABLE organization_child_relationships DROP CONSTRAINT IF EXISTS fk_organizations_organization_child_relationship;
+DROP INDEX IF EXISTS idx_organization_child_relationships_organization_id;
+CREATE INDEX idx_organization_child_relationships_organization_id ON organization_child_relationships(organization_id);
+ALTER TABLE organization_parent_relationships DROP CONSTRAINT IF EXISTS fk_organizations_organization_parent_relationship;
+DROP INDEX IF EXISTS idx_organization_parent_relationships_organization_id;
+CREATE INDEX idx_organization_parent_relationships_organization_id ON organization_parent_relationships(organization_id);
+
+ALTER TABLE individuals DROP CONSTRAINT individuals_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE individuals DROP COLUMN tenant_id;
+ALTER TABLE organizations DROP CONSTRAINT organizations_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organizations DROP COLUMN tenant_id;
+ALTER TABLE event_subscriptions DROP CONSTRAINT event_subscriptions_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE event_subscriptions DROP COLUMN tenant_id;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey, ADD PRIMARY KEY (scope, key);
+ALTER TABLE idempotency_keys DROP COLUMN tenant_id;
+ALTER TABLE contact_media DROP CONSTRAINT contact_media_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE contact_media DROP COLUMN tenant_id;
+ALTER TABLE external_references DROP CONSTRAINT external_references_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE external_references DROP COLUMN tenant_id;
+ALTER TABLE individual_identifications DROP CONSTRAINT individual_identifications_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE individual_identifications DROP COLUMN tenant_id;
+ALTER TABLE party_characteristics DROP CONSTRAINT party_characteristics_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE party_characteristics DROP COLUMN tenant_id;
+ALTER TABLE organization_contact_media DROP CONSTRAINT organization_contact_media_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organization_contact_media DROP COLUMN tenant_id;
+ALTER TABLE organization_external_references DROP CONSTRAINT organization_external_references_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organization_external_references DROP COLUMN tenant_id;
+ALTER TABLE organization_identifications DROP CONSTRAINT organization_identifications_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organization_identifications DROP COLUMN tenant_id;
+ALTER TABLE organization_child_relationships DROP CONSTRAINT organization_child_relationships_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organization_child_relationships DROP COLUMN tenant_id;
+ALTER TABLE organization_parent_relationships DROP CONSTRAINT organization_parent_relationships_pkey, ADD PRIMARY KEY (id);
+ALTER TABLE organization_parent_relationships DROP COLUMN tenant_id;
+ALTER TABLE outbox_events DROP COLUMN tenant_id;
+
+ALTER TABLE contact_media ADD CONSTRAINT fk_individuals_contact_medium
+    FOREIGN KEY (individual_id) REFERENCES individuals(id);
+ALTER TABLE external_references ADD CONSTRAINT fk_individuals_external_reference
+    FOREIGN KEY (individual_id) REFERENCES individuals(id);
+ALTER TABLE individual_identifications ADD CONSTRAINT fk_individuals_individual_identification
+    FOREIGN KEY (individual_id) REFERENCES individuals(id);
+ALTER TABLE party_characteristics ADD CONSTRAINT fk_individuals_party_characteristic
+    FOREIGN KEY (individual_id) REFERENCES individuals(id);
+ALTER TABLE organization_contact_media ADD CONSTRAINT fk_organizations_contact_medium
+    FOREIGN KEY (organization_id) REFERENCES organizations(id);
+ALTER TABLE organization_external_references ADD CONSTRAINT fk_organizations_external_reference
+    FOREIGN KEY (organization_id) REFERENCES organizations(id);
+ALTER TABLE organization_identifications ADD CONSTRAINT fk_organizations_organization_identification
+    FOREIGN KEY (organization_id) REFERENCES organizations(id);
+ALTER TABLE organization_child_relationships ADD CONSTRAINT fk_organizations_organization_child_relationship
+    FOREIGN KEY (organization_id) REFERENCES organizations(id);
+ALTER TABLE organization_parent_relationships ADD CONSTRAINT fk_organizations_organization_parent_relationship
+    FOREIGN KEY (organization_id) REFERENCES organizations(id);
@@ -0,0 +1,156 @@
+-- db/migrations/postgres/000007_add_tenants.up.sql
+-- Every party, sub-resource, subscription and idempotency key belongs to a
+-- tenant, existing rows to the default one. Ids become unique per tenant,
+-- and sub-resources can only reference a party of their own tenant.
+--
+-- Row-level security hides the rows of other tenants unless app.tenant_id
+-- names their tenant, or is '*' for the service's background work. FORCE
+-- applies the policies to the table owner as well; superusers and roles
+-- with BYPASSRLS are still exempt, so run the service as an ordinary role.
+-- Later migrations changing these rows must SET LOCAL app.tenant_id = '*'.
+
+ALTER TABLE contact_media DROP CONSTRAINT IF EXISTS fk_individuals_contact_medium;
+ALTER TABLE external_references DROP CONSTRAINT IF EXISTS fk_individuals_external_reference;
+ALTER TABLE individual_identifications DROP CONSTRAINT IF EXISTS fk_individuals_individual_identification;
+ALTER TABLE party_characteristics DROP CONSTRAINT IF EXISTS fk_individuals_party_characteristic;
+ALTER TABLE organization_contact_media DROP CONSTRAINT IF EXISTS fk_organizations_contact_medium;
+ALTER TABLE organization_external_references DROP CONSTRAINT IF EXISTS fk_organizations_external_reference;
+ALTER TABLE organization_identifications DROP CONSTRAINT IF EXISTS fk_organizations_organization_identification;
+ALTER TABLE organization_child_relationships DROP CONSTRAINT IF EXISTS fk_organizations_organization_child_relationship;
+ALTER TABLE organization_parent_relationships DROP CONSTRAINT IF EXISTS fk_organizations_organization_parent_relationship;
+
+ALTER TABLE individuals ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE individuals ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE individuals DROP CONSTRAINT individuals_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organizations ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organizations ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organizations DROP CONSTRAINT organizations_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE event_subscriptions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE event_subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE event_subscriptions DROP CONSTRAINT event_subscriptions_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE idempotency_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey, ADD PRIMARY KEY (tenant_id, scope, key);
+ALTER TABLE contact_media ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE contact_media ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE contact_media DROP CONSTRAINT contact_media_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE external_references ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE external_references ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE external_references DROP CONSTRAINT external_references_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE individual_identifications ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE individual_identifications ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE individual_identifications DROP CONSTRAINT individual_identifications_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE party_characteristics ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE party_characteristics ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE party_characteristics DROP CONSTRAINT party_characteristics_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organization_contact_media ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organization_contact_media ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organization_contact_media DROP CONSTRAINT organization_contact_media_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organization_external_references ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organization_external_references ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organization_external_references DROP CONSTRAINT organization_external_references_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organization_identifications ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organization_identifications ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organization_identifications DROP CONSTRAINT organization_identifications_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organization_child_relationships ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organization_child_relationships ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organization_child_relationships DROP CONSTRAINT organization_child_relationships_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE organization_parent_relationships ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE organization_parent_relationships ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE organization_parent_relationships DROP CONSTRAINT organization_parent_relationships_pkey, ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE outbox_events ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE outbox_events ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE contact_media ADD CONSTRAINT fk_individuals_contact_medium
+    FOREIGN KEY (tenant_id, individual_id) REFERENCES individuals(tenant_id, id);
+DROP INDEX IF EXISTS idx_contact_media_individual_id;
+CREATE INDEX idx_contact_media_individual_id ON contact_media(tenant_id, individual_id);
+ALTER TABLE external_references ADD CONSTRAINT fk_individuals_external_reference
+    FOREIGN KEY (tenant_id, individual_id) REFERENCES individuals(tenant_id, id);
+DROP INDEX IF EXISTS idx_external_references_individual_id;
+CREATE INDEX idx_external_references_individual_id ON external_references(tenant_id, individual_id);
+ALTER TABLE individual_identifications ADD CONSTRAINT fk_individuals_individual_identification
+    FOREIGN KEY (tenant_id, individual_id) REFERENCES individuals(tenant_id, id);
+DROP INDEX IF EXISTS idx_individual_identifications_individual_id;
+CREATE INDEX idx_individual_identifications_individual_id ON individual_identifications(tenant_id, individual_id);
+ALTER TABLE party_characteristics ADD CONSTRAINT fk_individuals_party_characteristic
+    FOREIGN KEY (tenant_id, individual_id) REFERENCES individuals(tenant_id, id);
+DROP INDEX IF EXISTS idx_party_characteristics_individual_id;
+CREATE INDEX idx_party_characteristics_individual_id ON party_characteristics(tenant_id, individual_id);
+ALTER TABLE organization_contact_media ADD CONSTRAINT fk_organizations_contact_medium
+    FOREIGN KEY (tenant_id, organization_id) REFERENCES organizations(tenant_id, id);
+DROP INDEX IF EXISTS idx_organization_contact_media_organization_id;
+CREATE INDEX idx_organization_contact_media_organization_id ON organization_contact_media(tenant_id, organization_id);
+ALTER TABLE organization_external_references ADD CONSTRAINT fk_organizations_external_reference
+    FOREIGN KEY (tenant_id, organization_id) REFERENCES organizations(tenant_id, id);
+DROP INDEX IF EXISTS idx_organization_external_references_organization_id;
+CREATE INDEX idx_organization_external_references_organization_id ON organization_external_references(tenant_id, organization_id);
+ALTER TABLE organization_identifications ADD CONSTRAINT fk_organizations_organization_identification
+    FOREIGN KEY (tenant_id, organization_id) REFERENCES organizations(tenant_id, id);
+DROP INDEX IF EXISTS idx_organization_identifications_organization_id;
+CREATE INDEX idx_organization_identifications_organization_id ON organization_identifications(tenant_id, organization_id);
+ALTER TABLE organization_child_relationships ADD CONSTRAINT fk_organizations_organization_child_relationship
+    FOREIGN KEY (tenant_id, organization_id) REFERENCES organizations(tenant_id, id);
+DROP INDEX IF EXISTS idx_organization_child_relationships_organization_id;
+CREATE INDEX idx_organization_child_relationships_organization_id ON organization_child_relationships(tenant_id, organization_id);
+ALTER TABLE organization_parent_relationships ADD CONSTRAINT fk_organizations_organization_parent_relationship
+    FOREIGN KEY (tenant_id, organization_id) REFERENCES organizations(tenant_id, id);
+DROP INDEX IF EXISTS idx_organization_parent_relationships_organization_id;
+CREATE INDEX idx_organization_parent_relationships_organization_id ON organization_parent_relationships(tenant_id, organization_id);
+
+ALTER TABLE individuals ENABLE ROW LEVEL SECURITY;
+ALTER TABLE individuals FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON individuals
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE contact_media ENABLE ROW LEVEL SECURITY;
+ALTER TABLE contact_media FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON contact_media
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE external_references ENABLE ROW LEVEL SECURITY;
+ALTER TABLE external_references FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON external_references
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE individual_identifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE individual_identifications FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON individual_identifications
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE party_characteristics ENABLE ROW LEVEL SECURITY;
+ALTER TABLE party_characteristics FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON party_characteristics
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organizations FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organizations
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organization_contact_media ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_contact_media FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_contact_media
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organization_external_references ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_external_references FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_external_references
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organization_identifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_identifications FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_identifications
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organization_child_relationships ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_child_relationships FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_child_relationships
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE organization_parent_relationships ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_parent_relationships FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_parent_relationships
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE event_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE event_subscriptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON event_subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE outbox_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE outbox_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON outbox_events
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.tenant_id', true) = '*');
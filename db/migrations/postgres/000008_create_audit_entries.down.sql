@@ -0,0 +1,3 @@
+-- db/migrations/postgres/000008_create_audit_entries.down.sql
+DROP TABLE IF EXISTS audit_entries;
+DROP FUNCTION IF EXISTS reject_audit_change();
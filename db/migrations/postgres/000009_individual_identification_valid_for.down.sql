@@ -0,0 +1,3 @@
+-- db/migrations/postgres/000009_individual_identification_valid_for.down.sql
+ALTER TABLE individual_identifications DROP COLUMN valid_for_start_date_time;
+ALTER TABLE individual_identifications RENAME COLUMN valid_for_end_date_time TO valid_for_end;
@@ -0,0 +1,8 @@
+-- db/migrations/postgres/000009_individual_identification_valid_for.up.sql
+-- validFor becomes a full TimePeriod. Identifications stored without an
+-- end got the zero time, which now reads as no end. The rows of every
+-- tenant are updated, which row-level security only allows with '*'.
+SET LOCAL app.tenant_id = '*';
+ALTER TABLE individual_identifications RENAME COLUMN valid_for_end TO valid_for_end_date_time;
+ALTER TABLE individual_identifications ADD COLUMN valid_for_start_date_time TIMESTAMPTZ;
+UPDATE individual_identifications SET valid_for_end_date_time = NULL WHERE valid_for_end_date_time < '0001-01-02';
@@ -0,0 +1,23 @@
+-- db/migrations/sqlite/000003_create_event_tables.up.sql
+CREATE TABLE IF NOT EXISTS event_subscriptions (
+    id VARCHAR(255) PRIMARY KEY,
+    callback TEXT NOT NULL,
+    query TEXT,
+    creation_date DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME,
+    created_at DATETIME,
+    sent_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_event_id ON outbox_events(event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events(status, next_attempt_at);
@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000004_create_idempotency_keys.down.sql
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,14 @@
+-- db/migrations/sqlite/000004_create_idempotency_keys.up.sql
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code BIGINT,
+    headers TEXT,
+    body TEXT,
+    created_at DATETIME,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000005_add_outbox_trace_parent.down.sql
+ALTER TABLE outbox_events DROP COLUMN trace_parent;
@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000005_add_outbox_trace_parent.up.sql
+ALTER TABLE outbox_events ADD COLUMN trace_parent VARCHAR(55);
@@ -0,0 +1,312 @@
+-- db/migrations/sqlite/000007_add_tenants.down.sql
+-- Fails, changing nothing, when two tenants use the same id.
+
+ALTER TABLE outbox_events DROP COLUMN tenant_id;
+
+DROP INDEX idx_individuals_deleted_at;
+DROP INDEX idx_individuals_given_name;
+DROP INDEX idx_individuals_family_name;
+DROP INDEX idx_organizations_deleted_at;
+DROP INDEX idx_organizations_name;
+DROP INDEX idx_idempotency_keys_expires_at;
+DROP INDEX idx_contact_media_deleted_at;
+DROP INDEX idx_contact_media_individual_id;
+DROP INDEX idx_external_references_deleted_at;
+DROP INDEX idx_external_references_individual_id;
+DROP INDEX idx_individual_identifications_deleted_at;
+DROP INDEX idx_individual_identifications_individual_id;
+DROP INDEX idx_party_characteristics_deleted_at;
+DROP INDEX idx_party_characteristics_individual_id;
+DROP INDEX idx_organization_contact_media_deleted_at;
+DROP INDEX idx_organization_contact_media_organization_id;
+DROP INDEX idx_organization_external_references_deleted_at;
+DROP INDEX idx_organization_external_references_organization_id;
+DROP INDEX idx_organization_identifications_deleted_at;
+DROP INDEX idx_organization_identifications_organization_id;
+DROP INDEX idx_organization_child_relationships_deleted_at;
+DROP INDEX idx_organization_child_relationships_organization_id;
+DROP INDEX idx_organization_parent_relationships_deleted_at;
+DROP INDEX idx_organization_parent_relationships_organization_id;
+
+ALTER TABLE individuals RENAME TO individuals_old;
+ALTER TABLE organizations RENAME TO organizations_old;
+ALTER TABLE event_subscriptions RENAME TO event_subscriptions_old;
+ALTER TABLE idempotency_keys RENAME TO idempotency_keys_old;
+ALTER TABLE contact_media RENAME TO contact_media_old;
+ALTER TABLE external_references RENAME TO external_references_old;
+ALTER TABLE individual_identifications RENAME TO individual_identifications_old;
+ALTER TABLE party_characteristics RENAME TO party_characteristics_old;
+ALTER TABLE organization_contact_media RENAME TO organization_contact_media_old;
+ALTER TABLE organization_external_references RENAME TO organization_external_references_old;
+ALTER TABLE organization_identifications RENAME TO organization_identifications_old;
+ALTER TABLE organization_child_relationships RENAME TO organization_child_relationships_old;
+ALTER TABLE organization_parent_relationships RENAME TO organization_parent_relationships_old;
+
+CREATE TABLE individuals (
+    id VARCHAR(255) NOT NULL,
+    href VARCHAR(255),
+    title VARCHAR(50),
+    given_name VARCHAR(255) NOT NULL,
+    family_name VARCHAR(255),
+    marital_status VARCHAR(1),
+    gender VARCHAR(1),
+    name_type VARCHAR(50),
+    nationality VARCHAR(3),
+    status VARCHAR(50),
+    creation_date DATETIME NOT NULL,
+    modification_date DATETIME NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id)
+);
+INSERT INTO individuals (id, href, title, given_name, family_name, marital_status, gender, name_type, nationality, status, creation_date, modification_date, created_by, modified_by, version, created_at, updated_at, deleted_at)
+    SELECT id, href, title, given_name, family_name, marital_status, gender, name_type, nationality, status, creation_date, modification_date, created_by, modified_by, version, created_at, updated_at, deleted_at FROM individuals_old;
+
+CREATE TABLE organizations (
+    id VARCHAR(255) NOT NULL,
+    href VARCHAR(255),
+    name VARCHAR(255) NOT NULL,
+    name_type VARCHAR(50),
+    trading_name VARCHAR(255),
+    organization_type VARCHAR(50),
+    is_legal_entity BOOLEAN,
+    is_head_office BOOLEAN,
+    status VARCHAR(50),
+    exists_during_start_date_time DATETIME,
+    exists_during_end_date_time DATETIME,
+    creation_date DATETIME NOT NULL,
+    modification_date DATETIME NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id)
+);
+INSERT INTO organizations (id, href, name, name_type, trading_name, organization_type, is_legal_entity, is_head_office, status, exists_during_start_date_time, exists_during_end_date_time, creation_date, modification_date, created_by, modified_by, created_at, updated_at, deleted_at)
+    SELECT id, href, name, name_type, trading_name, organization_type, is_legal_entity, is_head_office, status, exists_during_start_date_time, exists_during_end_date_time, creation_date, modification_date, created_by, modified_by, created_at, updated_at, deleted_at FROM organizations_old;
+
+CREATE TABLE event_subscriptions (
+    id VARCHAR(255) NOT NULL,
+    callback TEXT NOT NULL,
+    query TEXT,
+    creation_date DATETIME,
+    roles TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (id)
+);
+INSERT INTO event_subscriptions (id, callback, query, creation_date, roles)
+    SELECT id, callback, query, creation_date, roles FROM event_subscriptions_old;
+
+CREATE TABLE idempotency_keys (
+    scope VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code BIGINT,
+    headers TEXT,
+    body TEXT,
+    created_at DATETIME,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+INSERT INTO idempotency_keys (scope, key, request_hash, status_code, headers, body, created_at, expires_at)
+    SELECT scope, key, request_hash, status_code, headers, body, created_at, expires_at FROM idempotency_keys_old;
+
+CREATE TABLE contact_media (
+    id VARCHAR(255) NOT NULL,
+    individual_id VARCHAR(255),
+    type VARCHAR(50),
+    medium_type VARCHAR(50),
+    preferred BOOLEAN,
+    phone_number VARCHAR(50),
+    email_address VARCHAR(255),
+    street1 VARCHAR(255),
+    street2 VARCHAR(255),
+    city VARCHAR(255),
+    state_or_province VARCHAR(255),
+    country VARCHAR(255),
+    post_code VARCHAR(20),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_individuals_contact_medium FOREIGN KEY (individual_id) REFERENCES individuals(id)
+);
+INSERT INTO contact_media (id, individual_id, type, medium_type, preferred, phone_number, email_address, street1, street2, city, state_or_province, country, post_code, created_at, updated_at, deleted_at)
+    SELECT id, individual_id, type, medium_type, preferred, phone_number, email_address, street1, street2, city, state_or_province, country, post_code, created_at, updated_at, deleted_at FROM contact_media_old;
+
+CREATE TABLE external_references (
+    id VARCHAR(255) NOT NULL,
+    individual_id VARCHAR(255),
+    name VARCHAR(255),
+    external_identifier_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_individuals_external_reference FOREIGN KEY (individual_id) REFERENCES individuals(id)
+);
+INSERT INTO external_references (id, individual_id, name, external_identifier_type, type, created_at, updated_at, deleted_at)
+    SELECT id, individual_id, name, external_identifier_type, type, created_at, updated_at, deleted_at FROM external_references_old;
+
+CREATE TABLE individual_identifications (
+    id VARCHAR(255) NOT NULL,
+    individual_id VARCHAR(255),
+    identification_type VARCHAR(50),
+    identification_id VARCHAR(255),
+    valid_for_end DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_individuals_individual_identification FOREIGN KEY (individual_id) REFERENCES individuals(id)
+);
+INSERT INTO individual_identifications (id, individual_id, identification_type, identification_id, valid_for_end, created_at, updated_at, deleted_at)
+    SELECT id, individual_id, identification_type, identification_id, valid_for_end, created_at, updated_at, deleted_at FROM individual_identifications_old;
+
+CREATE TABLE party_characteristics (
+    id VARCHAR(255) NOT NULL,
+    individual_id VARCHAR(255),
+    name VARCHAR(255),
+    value VARCHAR(255),
+    value_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_individuals_party_characteristic FOREIGN KEY (individual_id) REFERENCES individuals(id)
+);
+INSERT INTO party_characteristics (id, individual_id, name, value, value_type, type, created_at, updated_at, deleted_at)
+    SELECT id, individual_id, name, value, value_type, type, created_at, updated_at, deleted_at FROM party_characteristics_old;
+
+CREATE TABLE organization_contact_media (
+    id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255),
+    type VARCHAR(50),
+    medium_type VARCHAR(50),
+    preferred BOOLEAN,
+    phone_number VARCHAR(50),
+    email_address VARCHAR(255),
+    street1 VARCHAR(255),
+    street2 VARCHAR(255),
+    city VARCHAR(255),
+    state_or_province VARCHAR(255),
+    country VARCHAR(255),
+    post_code VARCHAR(20),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organizations_contact_medium FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+INSERT INTO organization_contact_media (id, organization_id, type, medium_type, preferred, phone_number, email_address, street1, street2, city, state_or_province, country, post_code, created_at, updated_at, deleted_at)
+    SELECT id, organization_id, type, medium_type, preferred, phone_number, email_address, street1, street2, city, state_or_province, country, post_code, created_at, updated_at, deleted_at FROM organization_contact_media_old;
+
+CREATE TABLE organization_external_references (
+    id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255),
+    name VARCHAR(255),
+    external_identifier_type VARCHAR(50),
+    type VARCHAR(50),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organizations_external_reference FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+INSERT INTO organization_external_references (id, organization_id, name, external_identifier_type, type, created_at, updated_at, deleted_at)
+    SELECT id, organization_id, name, external_identifier_type, type, created_at, updated_at, deleted_at FROM organization_external_references_old;
+
+CREATE TABLE organization_identifications (
+    id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255),
+    identification_type VARCHAR(50),
+    identification_id VARCHAR(255),
+    issuing_authority VARCHAR(255),
+    issuing_date DATETIME,
+    valid_for_start_date_time DATETIME,
+    valid_for_end_date_time DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organizations_organization_identification FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+INSERT INTO organization_identifications (id, organization_id, identification_type, identification_id, issuing_authority, issuing_date, valid_for_start_date_time, valid_for_end_date_time, created_at, updated_at, deleted_at)
+    SELECT id, organization_id, identification_type, identification_id, issuing_authority, issuing_date, valid_for_start_date_time, valid_for_end_date_time, created_at, updated_at, deleted_at FROM organization_identifications_old;
+
+CREATE TABLE organization_child_relationships (
+    id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255),
+    relationship_type VARCHAR(50),
+    child_id VARCHAR(255),
+    child_href VARCHAR(255),
+    child_name VARCHAR(255),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organizations_organization_child_relationship FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+INSERT INTO organization_child_relationships (id, organization_id, relationship_type, child_id, child_href, child_name, created_at, updated_at, deleted_at)
+    SELECT id, organization_id, relationship_type, child_id, child_href, child_name, created_at, updated_at, deleted_at FROM organization_child_relationships_old;
+
+CREATE TABLE organization_parent_relationships (
+    id VARCHAR(255) NOT NULL,
+    organization_id VARCHAR(255),
+    relationship_type VARCHAR(50),
+    parent_id VARCHAR(255),
+    parent_href VARCHAR(255),
+    parent_name VARCHAR(255),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_organizations_organization_parent_relationship FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+INSERT INTO organization_parent_relationships (id, organization_id, relationship_type, parent_id, parent_href, parent_name, created_at, updated_at, deleted_at)
+    SELECT id, organization_id, relationship_type, parent_id, parent_href, parent_name, created_at, updated_at, deleted_at FROM organization_parent_relationships_old;
+
+DROP TABLE contact_media_old;
+DROP TABLE external_references_old;
+DROP TABLE individual_identifications_old;
+DROP TABLE party_characteristics_old;
+DROP TABLE organization_contact_media_old;
+DROP TABLE organization_external_references_old;
+DROP TABLE organization_identifications_old;
+DROP TABLE organization_child_relationships_old;
+DROP TABLE organization_parent_relationships_old;
+DROP TABLE individuals_old;
+DROP TABLE organizations_old;
+DROP TABLE event_subscriptions_old;
+DROP TABLE idempotency_keys_old;
+
+CREATE INDEX IF NOT EXISTS idx_individuals_deleted_at ON individuals(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_individuals_given_name ON individuals(given_name);
+CREATE INDEX IF NOT EXISTS idx_individuals_family_name ON individuals(family_name);
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organizations_name ON organizations(name);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+CREATE INDEX IF NOT EXISTS idx_contact_media_deleted_at ON contact_media(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_contact_media_individual_id ON contact_media(individual_id);
+CREATE INDEX IF NOT EXISTS idx_external_references_deleted_at ON external_references(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_external_references_individual_id ON external_references(individual_id);
+CREATE INDEX IF NOT EXISTS idx_individual_identifications_deleted_at ON individual_identifications(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_individual_identifications_individual_id ON individual_identifications(individual_id);
+CREATE INDEX IF NOT EXISTS idx_party_characteristics_deleted_at ON party_characteristics(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_party_characteristics_individual_id ON party_characteristics(individual_id);
+CREATE INDEX IF NOT EXISTS idx_organization_contact_media_deleted_at ON organization_contact_media(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_contact_media_organization_id ON organization_contact_media(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_external_references_deleted_at ON organization_external_references(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_external_references_organization_id ON organization_external_references(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_identifications_deleted_at ON organization_identifications(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_identifications_organization_id ON organization_identifications(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_child_relationships_deleted_at ON organization_child_relationships(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_child_relationships_organization_id ON organization_child_relationships(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_parent_relationships_deleted_at ON organization_parent_relationships(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_organization_parent_relationships_organization_id ON organization_parent_relationships(organization_id);
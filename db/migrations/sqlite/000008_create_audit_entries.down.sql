@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000008_create_audit_entries.down.sql
+DROP TABLE IF EXISTS audit_entries;
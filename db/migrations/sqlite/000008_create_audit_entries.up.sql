@@ -0,0 +1,31 @@
+-- db/migrations/sqlite/000008_create_audit_entries.up.sql
+-- The audit trail is append-only: the triggers reject any update or
+-- delete.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    sequence BIGINT NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    recorded_at DATETIME NOT NULL,
+    state_before TEXT NOT NULL DEFAULT '',
+    state_after TEXT NOT NULL DEFAULT '',
+    previous_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_entries_trail ON audit_entries(tenant_id, resource_type, resource_id, sequence);
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_update
+    BEFORE UPDATE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit entries cannot be changed or deleted');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete
+    BEFORE DELETE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit entries cannot be changed or deleted');
+END;
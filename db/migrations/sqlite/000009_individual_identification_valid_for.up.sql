@@ -0,0 +1,6 @@
+-- db/migrations/sqlite/000009_individual_identification_valid_for.up.sql
+-- validFor becomes a full TimePeriod. Identifications stored without an
+-- end got the zero time, which now reads as no end.
+ALTER TABLE individual_identifications RENAME COLUMN valid_for_end TO valid_for_end_date_time;
+ALTER TABLE individual_identifications ADD COLUMN valid_for_start_date_time DATETIME;
+UPDATE individual_identifications SET valid_for_end_date_time = NULL WHERE valid_for_end_date_time < '0001-01-02';
@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000010_add_subscription_owner.down.sql
+ALTER TABLE event_subscriptions DROP COLUMN owner;
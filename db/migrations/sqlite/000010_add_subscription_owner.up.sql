@@ -0,0 +1,3 @@
+-- db/migrations/sqlite/000010_add_subscription_owner.up.sql
+-- Listeners registered before owners were recorded belong to no subject
+ALTER TABLE event_subscriptions ADD COLUMN owner TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- db/migrations/sqlite/000011_add_outbox_delivered.down.sql
+ALTER TABLE outbox_events DROP COLUMN delivered;
@@ -0,0 +1,3 @@
+-- db/migrations/sqlite/000011_add_outbox_delivered.up.sql
+-- Events queued before deliveries were tracked are retried to every subscriber
+ALTER TABLE outbox_events ADD COLUMN delivered TEXT NOT NULL DEFAULT '';
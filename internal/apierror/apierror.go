@@ -0,0 +1,266 @@
+// internal/apierror/apierror.go
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	logging "github.com/your-username/tmf632-service/internal/logger"
+	"github.com/your-username/tmf632-service/internal/patch"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/repository"
+	"github.com/your-username/tmf632-service/internal/validation"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Stable, machine-readable error codes. Clients switch on these, so
+// existing values must never change meaning.
+const (
+	CodeInvalidBody              = "INVALID_BODY"
+	CodeValidationFailed         = "VALIDATION_FAILED"
+	CodeInvalidQuery             = "INVALID_QUERY"
+	CodeInvalidPatch             = "INVALID_PATCH"
+	CodeReadOnlyAttribute        = "READ_ONLY_ATTRIBUTE"
+	CodePatchNotApplicable       = "PATCH_NOT_APPLICABLE"
+	CodeUnsupportedMediaType     = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotFound                 = "NOT_FOUND"
+	CodeRouteNotFound            = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed         = "METHOD_NOT_ALLOWED"
+	CodeConflict                 = "CONFLICT"
+	CodePreconditionFailed       = "PRECONDITION_FAILED"
+	CodePreconditionRequired     = "PRECONDITION_REQUIRED"
+	CodeIdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeReferenceNotFound        = "REFERENCE_NOT_FOUND"
+	CodeRateLimited              = "RATE_LIMITED"
+	CodeUnauthorized             = "UNAUTHORIZED"
+	CodeAuthUnavailable          = "AUTH_UNAVAILABLE"
+	CodeForbidden                = "FORBIDDEN"
+	CodeTenantRequired           = "TENANT_REQUIRED"
+	CodeTimeout                  = "TIMEOUT"
+	CodeDatabaseError            = "DATABASE_ERROR"
+	CodeInternalError            = "INTERNAL_ERROR"
+)
+
+// Error is the TMF630 Error entity. Status holds the HTTP status code as a
+// string, as the specification defines it.
+type Error struct {
+	Code           string `json:"code"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ReferenceError string `json:"referenceError,omitempty"`
+	Type           string `json:"@type"`
+	BaseType       string `json:"@baseType,omitempty"`
+
+	// Details carries structured information about the failure, such as
+	// individual validation violations.
+	Details interface{} `json:"details,omitempty"`
+
+	httpStatus int
+	cause      error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// HTTPStatus is the status code the error is rendered with.
+func (e *Error) HTTPStatus() int {
+	return e.httpStatus
+}
+
+// New builds an Error for httpStatus with the standard reason phrase.
+func New(httpStatus int, code, message string) *Error {
+	return &Error{
+		Code:       code,
+		Reason:     http.StatusText(httpStatus),
+		Message:    message,
+		Status:     strconv.Itoa(httpStatus),
+		Type:       "Error",
+		httpStatus: httpStatus,
+	}
+}
+
+// Wrap records cause on e for logging; the cause is never sent to clients.
+func (e *Error) Wrap(cause error) *Error {
+	e.cause = cause
+	return e
+}
+
+// WithDetails attaches structured details to e.
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+func BadRequest(code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+func NotFound(resource, id string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, fmt.Sprintf("%s %s not found", resource, id))
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// PreconditionFailed reports an If-Match that no longer matches the stored
+// resource.
+func PreconditionFailed(resource, id string) *Error {
+	return New(http.StatusPreconditionFailed, CodePreconditionFailed,
+		fmt.Sprintf("%s %s has been modified; fetch it again and retry", resource, id))
+}
+
+// PreconditionRequired reports a change sent without the If-Match header
+// that would stop it overwriting changes the client has not seen.
+func PreconditionRequired(resource, id string) *Error {
+	return New(http.StatusPreconditionRequired, CodePreconditionRequired,
+		fmt.Sprintf("Changing %s %s requires an If-Match header with its ETag", resource, id))
+}
+
+// Database reports a failed storage operation without leaking SQL. Causes
+// From recognises, such as a duplicate key or an invalid patch, keep their
+// own code.
+func Database(message string, cause error) *Error {
+	if mapped := From(cause); mapped.Code != CodeInternalError {
+		return mapped
+	}
+	return New(http.StatusInternalServerError, CodeDatabaseError, message).Wrap(cause)
+}
+
+// Unauthorized reports a request without valid credentials.
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden reports an authenticated caller lacking the permission an
+// operation requires.
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+func Internal(message string, cause error) *Error {
+	return New(http.StatusInternalServerError, CodeInternalError, message).Wrap(cause)
+}
+
+// From converts any error returned by a handler into a TMF Error.
+// Recognised domain and storage errors get their specific code; anything
+// else becomes an INTERNAL_ERROR.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var violations validation.Errors
+	if errors.As(err, &violations) {
+		return BadRequest(CodeValidationFailed, "The request document is invalid").WithDetails(violations)
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return fromHTTPError(httpErr)
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, repository.ErrNotFound):
+		return New(http.StatusNotFound, CodeNotFound, "Resource not found").Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.Is(err, repository.ErrDuplicate):
+		return Conflict("A resource with the same identifier already exists").Wrap(err)
+	case errors.Is(err, repository.ErrVersionConflict):
+		return New(http.StatusPreconditionFailed, CodePreconditionFailed, "The resource has been modified; fetch it again and retry").Wrap(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return New(http.StatusUnprocessableEntity, CodeReferenceNotFound, "A referenced resource does not exist").Wrap(err)
+	case errors.Is(err, query.ErrInvalidFilter), errors.Is(err, query.ErrInvalidSort),
+		errors.Is(err, query.ErrInvalidFields), errors.Is(err, query.ErrInvalidPage):
+		return BadRequest(CodeInvalidQuery, err.Error())
+	case errors.Is(err, patch.ErrUnsupportedContentType):
+		return New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, err.Error())
+	case errors.Is(err, patch.ErrInvalidPatch):
+		return BadRequest(CodeInvalidPatch, err.Error())
+	case errors.Is(err, patch.ErrReadOnly):
+		return BadRequest(CodeReadOnlyAttribute, err.Error())
+	case errors.Is(err, patch.ErrNotApplicable):
+		return New(http.StatusUnprocessableEntity, CodePatchNotApplicable, err.Error())
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return New(http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return New(http.StatusConflict, CodeIdempotencyKeyInProgress, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(http.StatusServiceUnavailable, CodeTimeout, "The request timed out").Wrap(err)
+	}
+
+	return Internal("An unexpected error occurred", err)
+}
+
+func fromHTTPError(httpErr *echo.HTTPError) *Error {
+	message := fmt.Sprint(httpErr.Message)
+
+	var code string
+	switch httpErr.Code {
+	case http.StatusNotFound:
+		code = CodeRouteNotFound
+	case http.StatusMethodNotAllowed:
+		code = CodeMethodNotAllowed
+	case http.StatusUnsupportedMediaType:
+		code = CodeUnsupportedMediaType
+	case http.StatusTooManyRequests:
+		code = CodeRateLimited
+	case http.StatusUnauthorized:
+		code = CodeUnauthorized
+	case http.StatusForbidden:
+		code = CodeForbidden
+	case http.StatusBadRequest:
+		// Echo reports bind failures this way
+		code = CodeInvalidBody
+	default:
+		if httpErr.Code >= http.StatusInternalServerError {
+			return Internal("An unexpected error occurred", httpErr)
+		}
+		code = "HTTP_" + strconv.Itoa(httpErr.Code)
+	}
+	return New(httpErr.Code, code, message).Wrap(httpErr.Internal)
+}
+
+// NewHTTPErrorHandler renders every error escaping a handler or middleware
+// as a TMF Error. Server-side failures are logged with their cause.
+func NewHTTPErrorHandler(logger *zap.SugaredLogger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		apiErr := From(err)
+		if apiErr.httpStatus >= http.StatusInternalServerError {
+			logging.WithTrace(c.Request().Context(), logger).Errorw("Request failed",
+				"method", c.Request().Method,
+				"uri", c.Request().RequestURI,
+				"code", apiErr.Code,
+				"error", err)
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(apiErr.httpStatus)
+		} else {
+			err = c.JSON(apiErr.httpStatus, apiErr)
+		}
+		if err != nil {
+			logger.Errorw("Failed to write error response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// internal/audit/audit.go
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBroken is returned by Verify for an audit trail that was altered, or
+// had entries removed, after it was written.
+var ErrBroken = errors.New("audit trail broken")
+
+// New returns the entry recording operation on the party resourceType/id,
+// with the party as it was before and after; before is nil for a creation
+// and after for a deletion. The entry is sealed when it is appended.
+func New(resourceType, id, operation, actor, requestID string, before, after interface{}) (models.AuditEntry, error) {
+	entry := models.AuditEntry{
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Operation:    operation,
+		Actor:        actor,
+		RequestID:    requestID,
+		// Postgres keeps microseconds; the hash must survive the round
+		// trip
+		RecordedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	var err error
+	if entry.StateBefore, err = state(before); err != nil {
+		return entry, err
+	}
+	if entry.StateAfter, err = state(after); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+func state(party interface{}) (string, error) {
+	if party == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(party)
+	return string(data), err
+}
+
+// Seal chains entry to previous, the latest entry of the same party or nil
+// for its first, and sets its hash.
+func Seal(entry, previous *models.AuditEntry) {
+	entry.Sequence, entry.PreviousHash = 1, ""
+	if previous != nil {
+		entry.Sequence, entry.PreviousHash = previous.Sequence+1, previous.Hash
+	}
+	entry.Hash = Hash(*entry)
+}
+
+// Hash returns the hex SHA-256 of everything entry records, including the
+// hash of the entry before it.
+func Hash(entry models.AuditEntry) string {
+	// Marshalling strings and numbers cannot fail
+	sealed, _ := json.Marshal([]interface{}{
+		entry.PreviousHash,
+		entry.TenantID,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Sequence,
+		entry.Operation,
+		entry.Actor,
+		entry.RequestID,
+		entry.RecordedAt.UTC().Format(time.RFC3339Nano),
+		entry.StateBefore,
+		entry.StateAfter,
+	})
+	sum := sha256.Sum256(sealed)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks that trail, every entry of one party in sequence order,
+// is the chain it was written as.
+func Verify(trail []models.AuditEntry) error {
+	previous := ""
+	for i, entry := range trail {
+		switch {
+		case entry.Sequence != int64(i+1):
+			return fmt.Errorf("%w: entry %d is missing", ErrBroken, i+1)
+		case entry.PreviousHash != previous:
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrBroken, entry.Sequence, i)
+		case Hash(entry) != entry.Hash:
+			return fmt.Errorf("%w: entry %d was altered", ErrBroken, entry.Sequence)
+		}
+		previous = entry.Hash
+	}
+	return nil
+}
+
+// Append seals entry onto the trail of its party and stores it in tx. The
+// latest entry is locked and the trail is unique per party and sequence,
+// so concurrent changes fail rather than fork it.
+func Append(tx *gorm.DB, entry *models.AuditEntry) error {
+	// The tenant is part of the hash, so it is set before sealing rather
+	// than when the entry is created
+	if id, ok := tenant.FromContext(tx.Statement.Context); ok {
+		entry.TenantID = id
+	}
+
+	var latest []models.AuditEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("resource_type = ? AND resource_id = ?", entry.ResourceType, entry.ResourceID).
+		Order("sequence DESC").
+		Limit(1).
+		Find(&latest).Error; err != nil {
+		return err
+	}
+
+	if len(latest) == 0 {
+		Seal(entry, nil)
+	} else {
+		Seal(entry, &latest[0])
+	}
+	return tx.Create(entry).Error
+}
@@ -0,0 +1,104 @@
+// internal/audit/audit_test.go
+package audit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/models"
+)
+
+// trail returns a sealed trail of a party created, updated and deleted.
+func trail(t *testing.T) []models.AuditEntry {
+	t.Helper()
+	ann := map[string]string{"id": "a1", "givenName": "Ann"}
+	anne := map[string]string{"id": "a1", "givenName": "Anne"}
+	steps := []struct {
+		operation     string
+		before, after interface{}
+	}{
+		{"create", nil, ann},
+		{"update", ann, anne},
+		{"delete", anne, nil},
+	}
+
+	var entries []models.AuditEntry
+	for _, step := range steps {
+		entry, err := New("individual", "a1", step.operation, "alice", "r1", step.before, step.after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.TenantID = "t1"
+		if len(entries) == 0 {
+			Seal(&entry, nil)
+		} else {
+			Seal(&entry, &entries[len(entries)-1])
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSeal(t *testing.T) {
+	entries := trail(t)
+	for i, entry := range entries {
+		if entry.Sequence != int64(i+1) {
+			t.Errorf("entry %d: sequence %d", i, entry.Sequence)
+		}
+		if entry.Hash == "" || entry.Hash != Hash(entry) {
+			t.Errorf("entry %d: hash %q", i, entry.Hash)
+		}
+	}
+	if entries[0].PreviousHash != "" || entries[0].StateBefore != "" {
+		t.Errorf("first entry: %+v", entries[0])
+	}
+	if entries[1].PreviousHash != entries[0].Hash || entries[2].PreviousHash != entries[1].Hash {
+		t.Error("entries are not chained")
+	}
+	if entries[2].StateAfter != "" || entries[2].StateBefore != `{"givenName":"Anne","id":"a1"}` {
+		t.Errorf("last entry: %+v", entries[2])
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if err := Verify(trail(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(nil); err != nil {
+		t.Errorf("empty trail: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]models.AuditEntry) []models.AuditEntry
+	}{
+		{"altered state", func(e []models.AuditEntry) []models.AuditEntry {
+			e[1].StateAfter = `{"id":"a1","givenName":"Eve"}`
+			return e
+		}},
+		{"altered tenant", func(e []models.AuditEntry) []models.AuditEntry {
+			e[0].TenantID = "t2"
+			return e
+		}},
+		{"resealed entry", func(e []models.AuditEntry) []models.AuditEntry {
+			e[1].Actor = "mallory"
+			e[1].Hash = Hash(e[1])
+			return e
+		}},
+		{"missing entry", func(e []models.AuditEntry) []models.AuditEntry {
+			return append(e[:1], e[2:]...)
+		}},
+		{"missing first entry", func(e []models.AuditEntry) []models.AuditEntry {
+			return e[1:]
+		}},
+		{"reordered entries", func(e []models.AuditEntry) []models.AuditEntry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		}},
+	}
+	for _, tt := range tests {
+		if err := Verify(tt.tamper(trail(t))); !errors.Is(err, ErrBroken) {
+			t.Errorf("%s: got %v, want ErrBroken", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// internal/audit/diff.go
+package audit
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Change is an attribute that differs between two states of a party. Path
+// is a JSON pointer into the state, except that entries of sub-resource
+// arrays are addressed by their id rather than their index.
+type Change struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff lists the changes from before to after, JSON documents decoded into
+// interface{} values. A nil state, before a creation or after a deletion,
+// differs from the other as a whole.
+func Diff(before, after interface{}) []Change {
+	changes := []Change{}
+	diff("", before, after, &changes)
+	return changes
+}
+
+func diff(path string, before, after interface{}, changes *[]Change) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			diffObjects(path, b, a, changes)
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			diffArrays(path, b, a, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Before: before, After: after})
+	}
+}
+
+func diffObjects(path string, before, after map[string]interface{}, changes *[]Change) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diff(path+"/"+escape(key), before[key], after[key], changes)
+	}
+}
+
+// diffArrays matches the entries of sub-resource arrays by id, so that
+// removing one entry is not reported as a change of every entry after it.
+// Other arrays are compared by index.
+func diffArrays(path string, before, after []interface{}, changes *[]Change) {
+	beforeIDs, beforeByID, beforeOK := byID(before)
+	afterIDs, afterByID, afterOK := byID(after)
+	if !beforeOK || !afterOK {
+		for i := 0; i < max(len(before), len(after)); i++ {
+			diff(path+"/"+strconv.Itoa(i), at(before, i), at(after, i), changes)
+		}
+		return
+	}
+
+	ids := beforeIDs
+	for _, id := range afterIDs {
+		if _, ok := beforeByID[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		diff(path+"/"+escape(id), beforeByID[id], afterByID[id], changes)
+	}
+}
+
+// byID indexes entries by their id attribute, reporting false unless every
+// entry is an object with a distinct id.
+func byID(entries []interface{}) ([]string, map[string]interface{}, bool) {
+	ids := make([]string, 0, len(entries))
+	index := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		object, _ := entry.(map[string]interface{})
+		id, _ := object["id"].(string)
+		if _, taken := index[id]; id == "" || taken {
+			return nil, nil, false
+		}
+		ids = append(ids, id)
+		index[id] = entry
+	}
+	return ids, index, true
+}
+
+func at(entries []interface{}, i int) interface{} {
+	if i < len(entries) {
+		return entries[i]
+	}
+	return nil
+}
+
+// escape encodes a JSON pointer reference token, RFC 6901.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,56 @@
+// internal/audit/diff_test.go
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          []Change
+	}{
+		{"unchanged", `{"a":1}`, `{"a":1}`, []Change{}},
+		{"creation", `null`, `{"a":1}`, []Change{{Path: "", After: map[string]interface{}{"a": 1.0}}}},
+		{"attributes", `{"a":1,"b":"x","c/d":true}`, `{"a":2,"c/d":true,"e":"y"}`, []Change{
+			{Path: "/a", Before: 1.0, After: 2.0},
+			{Path: "/b", Before: "x"},
+			{Path: "/e", After: "y"},
+		}},
+		{"escaped keys", `{"c/d~":1}`, `{"c/d~":2}`, []Change{{Path: "/c~1d~0", Before: 1.0, After: 2.0}}},
+		{
+			"sub-resources by id",
+			`{"contactMedium":[{"id":"c1","mediumType":"email"},{"id":"c2","mediumType":"phone"}]}`,
+			`{"contactMedium":[{"id":"c2","mediumType":"mobile"},{"id":"c3","mediumType":"fax"}]}`,
+			[]Change{
+				{Path: "/contactMedium/c1", Before: map[string]interface{}{"id": "c1", "mediumType": "email"}},
+				{Path: "/contactMedium/c2/mediumType", Before: "phone", After: "mobile"},
+				{Path: "/contactMedium/c3", After: map[string]interface{}{"id": "c3", "mediumType": "fax"}},
+			},
+		},
+		{"arrays by index", `{"tags":["a","b"]}`, `{"tags":["b"]}`, []Change{
+			{Path: "/tags/0", Before: "a", After: "b"},
+			{Path: "/tags/1", Before: "b"},
+		}},
+		// Without distinct ids, entries fall back to their index
+		{"duplicate ids", `{"x":[{"id":"1","v":1},{"id":"1","v":2}]}`, `{"x":[{"id":"1","v":2}]}`, []Change{
+			{Path: "/x/0/v", Before: 1.0, After: 2.0},
+			{Path: "/x/1", Before: map[string]interface{}{"id": "1", "v": 2.0}},
+		}},
+	}
+	for _, tt := range tests {
+		var before, after interface{}
+		if err := json.Unmarshal([]byte(tt.before), &before); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.after), &after); err != nil {
+			t.Fatal(err)
+		}
+		if got := Diff(before, after); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
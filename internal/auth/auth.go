@@ -0,0 +1,106 @@
+// internal/auth/auth.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/logger"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ErrUnavailable marks a token that could not be checked because the
+// identity provider could not be reached, as opposed to an invalid token.
+var ErrUnavailable = errors.New("token verification unavailable")
+
+// Authenticator turns a bearer token into a Principal. Tokens shaped like a
+// JWT are verified locally by JWT; other tokens are handed to
+// Introspector. Either may be nil, rejecting that kind of token.
+type Authenticator struct {
+	JWT          *JWTVerifier
+	Introspector Introspector
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if strings.Count(token, ".") == 2 {
+		if a.JWT == nil {
+			return nil, errors.New("JWTs are not accepted")
+		}
+		return a.JWT.Verify(ctx, token)
+	}
+	if a.Introspector == nil {
+		return nil, errors.New("opaque tokens are not accepted")
+	}
+	return a.Introspector.Introspect(ctx, token)
+}
+
+// Middleware requires a valid bearer token on every request and stores the
+// caller's Principal in the request context. Requests without one are
+// rejected with 401 and a WWW-Authenticate challenge (RFC 6750).
+func Middleware(a *Authenticator, log *zap.SugaredLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			ctx := req.Context()
+
+			scheme, token, _ := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " ")
+			if !strings.EqualFold(scheme, "Bearer") || token == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="tmf632"`)
+				return apierror.Unauthorized("A bearer token is required")
+			}
+
+			principal, err := a.Authenticate(ctx, strings.TrimSpace(token))
+			if errors.Is(err, ErrUnavailable) {
+				logger.WithTrace(ctx, log).Errorw("Failed to verify bearer token",
+					"error", err,
+					"duration", time.Since(start))
+				return apierror.New(http.StatusServiceUnavailable, apierror.CodeAuthUnavailable,
+					"Credentials cannot be verified right now; retry later").Wrap(err)
+			}
+			if err != nil {
+				logger.WithTrace(ctx, log).Warnw("Rejected bearer token",
+					"error", err,
+					"duration", time.Since(start))
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+					fmt.Sprintf(`Bearer realm="tmf632", error="invalid_token", error_description=%q`, "The access token is invalid or expired"))
+				return apierror.Unauthorized("The access token is invalid or expired")
+			}
+
+			trace.SpanFromContext(ctx).SetAttributes(semconv.EnduserID(principal.Subject))
+			c.SetRequest(req.WithContext(NewContext(ctx, principal)))
+			return next(c)
+		}
+	}
+}
+
+// Require lets a request through only when policy grants its Principal
+// perm, and rejects it with 403 otherwise. It runs after Middleware.
+func Require(policy *Policy, perm Permission, log *zap.SugaredLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			principal, ok := FromContext(ctx)
+			if !ok {
+				return apierror.Unauthorized("A bearer token is required")
+			}
+			if !policy.Allows(principal, perm) {
+				logger.WithTrace(ctx, log).Warnw("Denied operation",
+					"subject", principal.Subject,
+					"permission", perm,
+					"scopes", principal.Scopes,
+					"roles", principal.Roles)
+				return apierror.Forbidden(fmt.Sprintf("The %s permission is required for this operation", perm))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// internal/auth/auth_test.go
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"go.uber.org/zap"
+)
+
+const (
+	issuer   = "https://idp.example.com"
+	audience = "tmf632"
+)
+
+// signer is a signing key and its public JWK.
+type signer struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{}
+	jwk    map[string]string
+}
+
+func rsaSigner(t *testing.T, kid string) signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer{kid, jwt.SigningMethodRS256, key, map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}}
+}
+
+func ecSigner(t *testing.T, kid string) signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer{kid, jwt.SigningMethodES256, key, map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32))),
+	}}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign returns a token by s with standard claims changed by change.
+func (s signer) sign(t *testing.T, change func(jwt.MapClaims)) string {
+	claims := jwt.MapClaims{
+		"iss":   issuer,
+		"aud":   []string{audience},
+		"sub":   "alice",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "party:read party:write",
+		"roles": []string{"agent"},
+	}
+	if change != nil {
+		change(claims)
+	}
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.kid != "" {
+		token.Header["kid"] = s.kid
+	}
+	raw, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// jwksServer serves the JWKS of the signers it holds and counts requests.
+type jwksServer struct {
+	*httptest.Server
+	signers  atomic.Value // []signer
+	requests atomic.Int32
+	down     atomic.Bool
+}
+
+func newJWKSServer(t *testing.T, signers ...signer) *jwksServer {
+	s := &jwksServer{}
+	s.signers.Store(signers)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var keys []map[string]string
+		for _, signer := range s.signers.Load().([]signer) {
+			keys = append(keys, signer.jwk)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rs, es := rsaSigner(t, "rs"), ecSigner(t, "es")
+	srv := newJWKSServer(t, rs, es)
+	v := &JWTVerifier{Keys: NewKeySet(srv.URL, "", time.Hour), Issuer: issuer, Audiences: []string{"other", audience}}
+
+	for _, s := range []signer{rs, es} {
+		p, err := v.Verify(context.Background(), s.sign(t, nil))
+		if err != nil {
+			t.Fatalf("%s: %v", s.kid, err)
+		}
+		if p.Subject != "alice" || strings.Join(p.Scopes, " ") != "party:read party:write" || strings.Join(p.Roles, " ") != "agent" {
+			t.Errorf("%s: got %+v", s.kid, p)
+		}
+	}
+	if srv.requests.Load() != 1 {
+		t.Errorf("JWKS was loaded %d times, want once", srv.requests.Load())
+	}
+
+	unknown := rsaSigner(t, "unknown")
+	noKid := rs
+	noKid.kid = ""
+	tests := map[string]string{
+		"expired":        rs.sign(t, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }),
+		"no expiry":      rs.sign(t, func(c jwt.MapClaims) { delete(c, "exp") }),
+		"other issuer":   rs.sign(t, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }),
+		"other audience": rs.sign(t, func(c jwt.MapClaims) { c["aud"] = "billing" }),
+		"unknown key":    unknown.sign(t, nil),
+		// A set of two keys has no default key
+		"no kid":   noKid.sign(t, nil),
+		"tampered": rs.sign(t, nil)[:20] + "x" + rs.sign(t, nil)[21:],
+		"HS256": func() string {
+			raw, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": issuer, "aud": audience, "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("secret"))
+			return raw
+		}(),
+	}
+	for name, token := range tests {
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	ctx := context.Background()
+	old, rotated := rsaSigner(t, "old"), rsaSigner(t, "new")
+	srv := newJWKSServer(t, old)
+	keys := NewKeySet(srv.URL, "", time.Hour)
+
+	if err := keys.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// A single key also serves tokens without a kid
+	if _, err := keys.Key(ctx, ""); err != nil {
+		t.Errorf("no kid: %v", err)
+	}
+
+	// Unknown kids reload the set, but no more than once per minReload
+	srv.signers.Store([]signer{old, rotated})
+	if _, err := keys.Key(ctx, "new"); err == nil {
+		t.Error("reloaded within minReload")
+	}
+	keys.attemptAt = time.Now().Add(-minReload)
+	if _, err := keys.Key(ctx, "new"); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+
+	// While the source is down the loaded keys keep being served
+	srv.down.Store(true)
+	keys.loadedAt, keys.attemptAt = time.Now().Add(-2*time.Hour), time.Now().Add(-minReload)
+	if _, err := keys.Key(ctx, "old"); err != nil {
+		t.Errorf("stale key: %v", err)
+	}
+	if err := keys.Refresh(ctx); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("refresh while down: got %v, want ErrUnavailable", err)
+	}
+
+	empty := NewKeySet(srv.URL, "", time.Hour)
+	if _, err := empty.Key(ctx, "old"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("first load while down: got %v, want ErrUnavailable", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rs := rsaSigner(t, "rs")
+	encryption := rsaSigner(t, "enc")
+	encryption.jwk["use"] = "enc"
+	badExponent := rsaSigner(t, "bad")
+	badExponent.jwk["e"] = b64([]byte{1})
+	offCurve := ecSigner(t, "off")
+	offCurve.jwk["y"] = offCurve.jwk["x"]
+
+	doc := func(signers ...signer) []byte {
+		var keys []map[string]string
+		for _, s := range signers {
+			keys = append(keys, s.jwk)
+		}
+		data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+		return data
+	}
+
+	keys, err := parseJWKS(doc(rs, encryption))
+	if err != nil || len(keys) != 1 || keys["rs"] == nil {
+		t.Errorf("encryption key not skipped: %v, %v", keys, err)
+	}
+	for name, data := range map[string][]byte{
+		"only encryption keys": doc(encryption),
+		"bad exponent":         doc(rs, badExponent),
+		"point off the curve":  doc(offCurve),
+		"not JSON":             []byte("<html>"),
+	} {
+		if _, err := parseJWKS(data); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+}
+
+func TestRemoteIntrospector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "svc" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.FormValue("token") {
+		case "good":
+			fmt.Fprint(w, `{"active":true,"sub":"bob","scope":"party:read","aud":"tmf632"}`)
+		case "other":
+			fmt.Fprint(w, `{"active":true,"sub":"bob","aud":["billing"]}`)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `{"active":false}`)
+		}
+	}))
+	defer srv.Close()
+	r := NewRemoteIntrospector(srv.URL, "svc", "s3cret", []string{audience})
+
+	p, err := r.Introspect(context.Background(), "good")
+	if err != nil || p.Subject != "bob" || len(p.Scopes) != 1 {
+		t.Errorf("good: got %+v, %v", p, err)
+	}
+	for _, token := range []string{"revoked", "other"} {
+		if _, err := r.Introspect(context.Background(), token); err == nil || errors.Is(err, ErrUnavailable) {
+			t.Errorf("%s: got %v, want a rejection", token, err)
+		}
+	}
+	if _, err := r.Introspect(context.Background(), "broken"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("broken: got %v, want ErrUnavailable", err)
+	}
+	r.ClientSecret = "wrong"
+	if _, err := r.Introspect(context.Background(), "good"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("wrong client secret: got %v, want ErrUnavailable", err)
+	}
+}
+
+// introspector answers every token with a fixed outcome.
+type introspector struct {
+	p   *Principal
+	err error
+}
+
+func (i introspector) Introspect(context.Context, string) (*Principal, error) {
+	return i.p, i.err
+}
+
+func TestMiddleware(t *testing.T) {
+	rs := rsaSigner(t, "rs")
+	srv := newJWKSServer(t, rs)
+	a := &Authenticator{
+		JWT:          &JWTVerifier{Keys: NewKeySet(srv.URL, "", time.Hour), Issuer: issuer, Audiences: []string{audience}},
+		Introspector: introspector{err: fmt.Errorf("%w: endpoint down", ErrUnavailable)},
+	}
+	var seen *Principal
+	handler := Middleware(a, zap.NewNop().Sugar())(func(c echo.Context) error {
+		seen, _ = FromContext(c.Request().Context())
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		authorization string
+		status        int
+		challenge     string
+	}{
+		{"Bearer " + rs.sign(t, nil), http.StatusNoContent, ""},
+		{"bearer " + rs.sign(t, nil), http.StatusNoContent, ""},
+		{"", http.StatusUnauthorized, `Bearer realm="tmf632"`},
+		{"Basic YWxpY2U6cHc=", http.StatusUnauthorized, `Bearer realm="tmf632"`},
+		{"Bearer " + rs.sign(t, func(c jwt.MapClaims) { c["aud"] = "billing" }), http.StatusUnauthorized, `error="invalid_token"`},
+		// Opaque tokens go to the introspection endpoint, which is down
+		{"Bearer opaque", http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		seen = nil
+		req := httptest.NewRequest(http.MethodGet, "/individual", nil)
+		if tt.authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		status := http.StatusNoContent
+		if err := handler(echo.New().NewContext(req, rec)); err != nil {
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("%q: %v", tt.authorization, err)
+			}
+			status = apiErr.HTTPStatus()
+		}
+		if status != tt.status || !strings.Contains(rec.Header().Get(echo.HeaderWWWAuthenticate), tt.challenge) {
+			t.Errorf("%q: got %d, challenge %q", tt.authorization, status, rec.Header().Get(echo.HeaderWWWAuthenticate))
+		}
+		if (status == http.StatusNoContent) != (seen != nil && seen.Subject == "alice") {
+			t.Errorf("%q: handler saw principal %+v", tt.authorization, seen)
+		}
+	}
+}
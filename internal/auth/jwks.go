@@ -0,0 +1,262 @@
+// internal/auth/jwks.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minReload is the shortest time between two loads of a key set, so tokens
+// naming made-up keys cannot flood the identity provider.
+const minReload = 30 * time.Second
+
+// KeySet holds the signing keys of a JSON Web Key Set read from a URL or a
+// file. The set is reloaded once it is older than the refresh interval and
+// when a token names a key it does not know, so key rotations are picked up
+// without a restart.
+type KeySet struct {
+	url, file string
+	refresh   time.Duration
+	client    *http.Client
+
+	// mu guards the fields below; it is never held while the set is read
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	loadedAt  time.Time
+	attemptAt time.Time
+	loading   *keyLoad
+}
+
+// keyLoad is a load of the key set in progress, which every caller needing
+// it waits for rather than starting its own.
+type keyLoad struct {
+	done chan struct{}
+	err  error
+}
+
+// NewKeySet returns a KeySet reading the JWKS at url, or from file when url
+// is empty. Nothing is loaded until Refresh or the first Key call.
+func NewKeySet(url, file string, refresh time.Duration) *KeySet {
+	return &KeySet{
+		url:     url,
+		file:    file,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key with id kid. A token without a kid may use
+// the only key of a single-key set. While the source is unreachable the
+// keys loaded last keep being served.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := s.lookup(kid)
+	var load *keyLoad
+	if !ok || time.Since(s.loadedAt) > s.refresh {
+		load = s.loading
+		if load == nil && time.Since(s.attemptAt) >= minReload {
+			load = s.startLoad(ctx)
+		}
+	}
+	s.mu.Unlock()
+
+	if load != nil {
+		if err := load.wait(ctx); err != nil && !ok {
+			return nil, err
+		}
+		s.mu.Lock()
+		key, ok = s.lookup(kid)
+		s.mu.Unlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// Refresh loads the key set now, or waits for the load in progress.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	load := s.loading
+	if load == nil {
+		load = s.startLoad(ctx)
+	}
+	s.mu.Unlock()
+	return load.wait(ctx)
+}
+
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// startLoad reads the key set in the background and swaps it in once
+// parsed. s.mu must be held. The load outlives the caller that started
+// it, as others may be waiting for it too.
+func (s *KeySet) startLoad(ctx context.Context) *keyLoad {
+	load := &keyLoad{done: make(chan struct{})}
+	s.loading, s.attemptAt = load, time.Now()
+
+	go func() {
+		keys, err := s.fetch(context.WithoutCancel(ctx))
+		s.mu.Lock()
+		if err == nil {
+			s.keys, s.loadedAt = keys, time.Now()
+		}
+		s.loading = nil
+		s.mu.Unlock()
+
+		load.err = err
+		close(load.done)
+	}()
+	return load
+}
+
+func (l *keyLoad) wait(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return l.err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: load JWKS: %v", ErrUnavailable, ctx.Err())
+	}
+}
+
+func (s *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: load JWKS: %v", ErrUnavailable, err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse JWKS: %v", ErrUnavailable, err)
+	}
+	return keys, nil
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if s.url == "" {
+		return os.ReadFile(s.file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d", s.url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk is the subset of RFC 7517 needed for RSA and EC signing keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS decodes the signing keys of a JWKS document by key id. Keys
+// meant for encryption and unsupported key types are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k)
+		case "EC":
+			key, err = ecKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RSA or EC signing keys")
+	}
+	return keys, nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func ecKey(k jwk) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("point is not on %s", k.Crv)
+	}
+	return key, nil
+}
@@ -0,0 +1,57 @@
+// internal/auth/jwt.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTVerifier validates signed JWT access tokens: an RS256 or ES256
+// signature by a key of Keys, the issuer, at least one of the audiences,
+// and the expiry.
+type JWTVerifier struct {
+	Keys      *KeySet
+	Issuer    string
+	Audiences []string
+	// Leeway tolerates clock skew with the issuer.
+	Leeway time.Duration
+}
+
+func (v *JWTVerifier) Verify(ctx context.Context, raw string) (*Principal, error) {
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.Keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(v.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.Leeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return nil, err
+	}
+	if !intersects(audiences, v.Audiences) {
+		return nil, errors.New("token is not meant for this service")
+	}
+	return principalFromClaims(claims), nil
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
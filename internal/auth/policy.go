@@ -0,0 +1,110 @@
+// internal/auth/policy.go
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/your-username/tmf632-service/internal/masking"
+	"gopkg.in/yaml.v3"
+)
+
+// Permission names an operation on the API a caller may be granted.
+type Permission string
+
+const (
+	PartyRead   Permission = "party:read"
+	PartyWrite  Permission = "party:write"
+	PartyDelete Permission = "party:delete"
+	PartyAudit  Permission = "party:audit"
+	HubWrite    Permission = "hub:write"
+)
+
+var permissions = []Permission{PartyRead, PartyWrite, PartyDelete, PartyAudit, HubWrite}
+
+// Policy grants permissions to the scopes and roles a token carries. A
+// principal holds the union of what its scopes and roles grant. Fields
+// shapes the attributes each role sees of the resources it may read.
+type Policy struct {
+	Scopes map[string][]Permission `yaml:"scopes"`
+	Roles  map[string][]Permission `yaml:"roles"`
+	Fields masking.Policy          `yaml:"fields"`
+}
+
+// DefaultPolicy grants each permission to the scope of the same name.
+func DefaultPolicy() *Policy {
+	p := &Policy{Scopes: make(map[string][]Permission, len(permissions))}
+	for _, perm := range permissions {
+		p.Scopes[string(perm)] = []Permission{perm}
+	}
+	return p
+}
+
+// LoadPolicy reads a YAML policy file, rejecting unknown keys and
+// permissions.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	var errs []error
+	check := func(kind string, grants map[string][]Permission) {
+		for name, perms := range grants {
+			for _, perm := range perms {
+				if !knownPermission(perm) {
+					errs = append(errs, fmt.Errorf("%s %q: unknown permission %q, use one of %v", kind, name, perm, permissions))
+				}
+			}
+		}
+	}
+	check("scope", p.Scopes)
+	check("role", p.Roles)
+	if err := p.Fields.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(p.Scopes) == 0 && len(p.Roles) == 0 {
+		errs = append(errs, errors.New("grants no permissions"))
+	}
+	return errors.Join(errs...)
+}
+
+func knownPermission(perm Permission) bool {
+	for _, known := range permissions {
+		if perm == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether any scope or role of principal grants perm.
+func (p *Policy) Allows(principal *Principal, perm Permission) bool {
+	return grants(p.Scopes, principal.Scopes, perm) || grants(p.Roles, principal.Roles, perm)
+}
+
+func grants(policy map[string][]Permission, held []string, perm Permission) bool {
+	for _, name := range held {
+		for _, granted := range policy[name] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
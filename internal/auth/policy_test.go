@@ -0,0 +1,112 @@
+// internal/auth/policy_test.go
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"go.uber.org/zap"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+	for _, perm := range permissions {
+		if !p.Allows(&Principal{Scopes: []string{string(perm)}}, perm) {
+			t.Errorf("scope %s does not grant itself", perm)
+		}
+	}
+	if p.Allows(&Principal{Scopes: []string{"party:read"}}, PartyWrite) {
+		t.Error("party:read grants party:write")
+	}
+	if p.Allows(&Principal{Roles: []string{"party:write"}}, PartyWrite) {
+		t.Error("a role named like a scope grants it")
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	// The example shipped with the service loads
+	p, err := LoadPolicy("../../policy.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		principal Principal
+		perm      Permission
+		want      bool
+	}{
+		{Principal{Roles: []string{"self-care"}}, PartyRead, true},
+		{Principal{Roles: []string{"self-care"}}, PartyWrite, false},
+		// Scopes and roles add up
+		{Principal{Scopes: []string{"party:delete"}, Roles: []string{"self-care"}}, PartyDelete, true},
+		{Principal{Roles: []string{"self-care", "auditor"}}, PartyAudit, true},
+		{Principal{Scopes: []string{"party:write"}}, PartyRead, true},
+		{Principal{}, PartyRead, false},
+	}
+	for _, tt := range tests {
+		if got := p.Allows(&tt.principal, tt.perm); got != tt.want {
+			t.Errorf("%+v %s: got %v, want %v", tt.principal, tt.perm, got, tt.want)
+		}
+	}
+	if len(p.Fields["individual"]) == 0 {
+		t.Error("field rules were not loaded")
+	}
+
+	dir := t.TempDir()
+	invalid := map[string]string{
+		"unknown permission": "roles:\n  agent: [party:read, party:update]\n",
+		"unknown key":        "scopes:\n  party:read: [party:read]\nrole:\n  agent: [party:read]\n",
+		"no grants":          "fields: {}\n",
+		"bad field rule":     "roles:\n  agent: [party:read]\nfields:\n  individual:\n    - path: givenName\n      default: blur\n",
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("%s: loaded", name)
+		}
+	}
+	if _, err := LoadPolicy(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("missing file: loaded")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	handler := Require(DefaultPolicy(), PartyDelete, zap.NewNop().Sugar())(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	tests := []struct {
+		principal *Principal
+		status    int
+	}{
+		{&Principal{Subject: "alice", Scopes: []string{"party:delete"}}, http.StatusNoContent},
+		{&Principal{Subject: "alice", Scopes: []string{"party:read", "party:write"}}, http.StatusForbidden},
+		// Without Middleware there is no principal
+		{nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/individual/i1", nil)
+		if tt.principal != nil {
+			req = req.WithContext(NewContext(req.Context(), tt.principal))
+		}
+		status := http.StatusNoContent
+		if err := handler(echo.New().NewContext(req, httptest.NewRecorder())); err != nil {
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatal(err)
+			}
+			status = apiErr.HTTPStatus()
+		}
+		if status != tt.status {
+			t.Errorf("%+v: got %d, want %d", tt.principal, status, tt.status)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// internal/auth/principal.go
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, from the token's sub claim.
+	Subject string
+	// Scopes are the OAuth2 scopes granted to the token, from its scope
+	// or scp claim.
+	Scopes []string
+	// Roles are the roles the token carries, from its roles claim.
+	Roles []string
+	// Claims holds every claim of the token or introspection response.
+	Claims map[string]interface{}
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying p.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the Principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
+
+func principalFromClaims(claims map[string]interface{}) *Principal {
+	p := &Principal{Claims: claims}
+	p.Subject, _ = claims["sub"].(string)
+	p.Scopes = stringList(claims["scope"])
+	if len(p.Scopes) == 0 {
+		p.Scopes = stringList(claims["scp"])
+	}
+	p.Roles = stringList(claims["roles"])
+	return p
+}
+
+// stringList reads a claim holding either a space separated string or an
+// array of strings.
+func stringList(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+		return items
+	}
+	return nil
+}
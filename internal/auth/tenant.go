@@ -0,0 +1,62 @@
+// internal/auth/tenant.go
+package auth
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tenant scopes each request to a tenant. The tenant comes from the
+// cfg.Claim claim of an authenticated caller's token, and otherwise from
+// the cfg.Header header, which is then trusted as set by a gateway in
+// front of the service. A header naming another tenant than the token is
+// rejected. With multi-tenancy disabled every request is in tenant.Default.
+func Tenant(cfg config.Tenancy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := tenant.Default
+			if cfg.Enabled {
+				var err error
+				if id, err = resolveTenant(c, cfg); err != nil {
+					return err
+				}
+			}
+
+			trace.SpanFromContext(req.Context()).SetAttributes(attribute.String("tenant.id", id))
+			c.SetRequest(req.WithContext(tenant.NewContext(req.Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+func resolveTenant(c echo.Context, cfg config.Tenancy) (string, error) {
+	header := c.Request().Header.Get(cfg.Header)
+
+	if principal, ok := FromContext(c.Request().Context()); ok && cfg.Claim != "" {
+		id, _ := principal.Claims[cfg.Claim].(string)
+		if !tenant.Valid(id) {
+			return "", apierror.Forbidden("The access token is not issued for a tenant")
+		}
+		if header != "" && header != id {
+			return "", apierror.Forbidden("The access token is not valid for tenant " + header)
+		}
+		return id, nil
+	}
+
+	if header == "" {
+		return "", apierror.New(http.StatusBadRequest, apierror.CodeTenantRequired,
+			"The "+cfg.Header+" header is required")
+	}
+	if !tenant.Valid(header) {
+		return "", apierror.New(http.StatusBadRequest, apierror.CodeTenantRequired,
+			"The "+cfg.Header+" header is not a valid tenant id")
+	}
+	return header, nil
+}
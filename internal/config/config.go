@@ -2,37 +2,423 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/netip"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/your-username/tmf632-service/internal/ids"
+	"github.com/your-username/tmf632-service/internal/migrate"
+	"github.com/your-username/tmf632-service/internal/repository"
 )
 
+// Config is the effective service configuration. Each setting is resolved
+// from, in increasing order of precedence:
+//
+//  1. the defaults below;
+//  2. the YAML or TOML file named by CONFIG_FILE, if any;
+//  3. the environment variable in its env tag, including those read from
+//     a .env file in the working directory;
+//  4. for settings tagged secret, the file named by the same variable with
+//     a _FILE suffix, e.g. DB_PASSWORD_FILE for a mounted Kubernetes secret.
+//     Setting both DB_PASSWORD and DB_PASSWORD_FILE is an error.
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	ServerPort string
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+
+	// Repository selects where parties are stored: "database" or
+	// "memory". The memory repository needs no database at all.
+	Repository string `yaml:"repository" toml:"repository" env:"REPOSITORY"`
+
+	API       API       `yaml:"api" toml:"api"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	Tenancy   Tenancy   `yaml:"tenancy" toml:"tenancy"`
+	CORS      CORS      `yaml:"cors" toml:"cors"`
+	RateLimit RateLimit `yaml:"rateLimit" toml:"rateLimit"`
+	Events    Events    `yaml:"events" toml:"events"`
+	Health    Health    `yaml:"health" toml:"health"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+}
+
+type Server struct {
+	Port string `yaml:"port" toml:"port" env:"SERVER_PORT"`
+
+	ReadTimeout       time.Duration `yaml:"readTimeout" toml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" toml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" toml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+
+	// DrainDelay is how long the server keeps serving after it reports
+	// not ready, so load balancers stop routing to it before the listener
+	// closes. ShutdownTimeout then bounds draining in-flight requests and
+	// stopping the background workers.
+	DrainDelay      time.Duration `yaml:"drainDelay" toml:"drainDelay" env:"SERVER_DRAIN_DELAY"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+
+	TLS TLS `yaml:"tls" toml:"tls"`
+}
+
+// TLS serves HTTPS when both files are set.
+type TLS struct {
+	CertFile string `yaml:"certFile" toml:"certFile" env:"SERVER_TLS_CERT"`
+	KeyFile  string `yaml:"keyFile" toml:"keyFile" env:"SERVER_TLS_KEY"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+type Database struct {
+	// Driver selects the database: "postgres", or "sqlite" for a
+	// single-binary deployment storing everything in the file at Path.
+	Driver string `yaml:"driver" toml:"driver" env:"DB_DRIVER"`
+	Path   string `yaml:"path" toml:"path" env:"DB_PATH"`
+
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" toml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslMode" toml:"sslMode" env:"DB_SSLMODE"`
+
+	MaxOpenConns    int           `yaml:"maxOpenConns" toml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" toml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" toml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" toml:"connMaxIdleTime" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// ConnectTimeout is how long startup keeps retrying an unreachable
+	// database before giving up.
+	ConnectTimeout time.Duration `yaml:"connectTimeout" toml:"connectTimeout" env:"DB_CONNECT_TIMEOUT"`
+
+	// AutoMigrate applies pending schema migrations at startup. Postgres
+	// replicas coordinate through an advisory lock, so it is safe to leave on.
+	AutoMigrate bool `yaml:"autoMigrate" toml:"autoMigrate" env:"AUTO_MIGRATE"`
+}
+
+type API struct {
+	// BasePath is the prefix every TMF632 route is mounted under and that
+	// resource hrefs are built from.
+	BasePath string `yaml:"basePath" toml:"basePath" env:"API_BASE_PATH"`
+
+	// DefaultPageSize is used by list endpoints when no limit is given and
+	// MaxPageSize caps any limit a client asks for.
+	DefaultPageSize int `yaml:"defaultPageSize" toml:"defaultPageSize" env:"DEFAULT_PAGE_SIZE"`
+	MaxPageSize     int `yaml:"maxPageSize" toml:"maxPageSize" env:"MAX_PAGE_SIZE"`
+
+	// IDStrategy selects how resource ids are generated: uuid or ulid.
+	IDStrategy string `yaml:"idStrategy" toml:"idStrategy" env:"ID_STRATEGY"`
+	// ImportMode lets clients supply their own ids on create, e.g. when
+	// migrating parties from another system.
+	ImportMode bool `yaml:"importMode" toml:"importMode" env:"IMPORT_MODE"`
+	// RequireIfMatch rejects a PUT, PATCH or DELETE of an individual sent
+	// without If-Match with 428, so that no client overwrites or removes
+	// changes it has not seen.
+	RequireIfMatch bool `yaml:"requireIfMatch" toml:"requireIfMatch" env:"REQUIRE_IF_MATCH"`
+
+	// IdempotencyTTL is how long the response to a request sent with an
+	// Idempotency-Key is kept for replay.
+	IdempotencyTTL time.Duration `yaml:"idempotencyTTL" toml:"idempotencyTTL" env:"IDEMPOTENCY_TTL"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error; Format is json or console.
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+// Auth configures bearer token authentication.
+type Auth struct {
+	Enabled   bool     `yaml:"enabled" toml:"enabled" env:"AUTH_ENABLED"`
+	Issuer    string   `yaml:"issuer" toml:"issuer" env:"AUTH_ISSUER"`
+	Audiences []string `yaml:"audiences" toml:"audiences" env:"AUTH_AUDIENCES"`
+	// Signing keys for JWTs are read from JWKSURL or the local JWKSFile,
+	// and reloaded every JWKSRefresh or when a token names an unknown key.
+	JWKSURL     string        `yaml:"jwksURL" toml:"jwksURL" env:"AUTH_JWKS_URL"`
+	JWKSFile    string        `yaml:"jwksFile" toml:"jwksFile" env:"AUTH_JWKS_FILE"`
+	JWKSRefresh time.Duration `yaml:"jwksRefresh" toml:"jwksRefresh" env:"AUTH_JWKS_REFRESH"`
+
+	// Opaque tokens are checked against the RFC 7662 introspection
+	// endpoint at IntrospectionURL, when set.
+	IntrospectionURL          string `yaml:"introspectionURL" toml:"introspectionURL" env:"AUTH_INTROSPECTION_URL"`
+	IntrospectionClientID     string `yaml:"introspectionClientID" toml:"introspectionClientID" env:"AUTH_INTROSPECTION_CLIENT_ID"`
+	IntrospectionClientSecret string `yaml:"introspectionClientSecret" toml:"introspectionClientSecret" env:"AUTH_INTROSPECTION_CLIENT_SECRET" secret:"true"`
+
+	// PolicyFile maps token scopes and roles to the operations they
+	// permit; without one, the scopes party:read, party:write and
+	// party:delete each permit their own operation.
+	PolicyFile string `yaml:"policyFile" toml:"policyFile" env:"AUTH_POLICY_FILE"`
+}
+
+// Tenancy separates the parties of several tenants, such as brands, served
+// by one deployment. Each request belongs to the tenant named by the Claim
+// claim of its access token or, without authentication, by the Header
+// header.
+type Tenancy struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled" env:"TENANCY_ENABLED"`
+	Claim   string `yaml:"claim" toml:"claim" env:"TENANCY_CLAIM"`
+	Header  string `yaml:"header" toml:"header" env:"TENANCY_HEADER"`
+}
+
+type CORS struct {
+	// AllowOrigins lists the origins browsers may call the API from; "*"
+	// allows any and an empty list disables CORS.
+	AllowOrigins []string `yaml:"allowOrigins" toml:"allowOrigins" env:"CORS_ALLOW_ORIGINS"`
+}
+
+// RateLimit caps requests per client IP with a token bucket.
+type RateLimit struct {
+	Enabled           bool    `yaml:"enabled" toml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" toml:"requestsPerSecond" env:"RATE_LIMIT_RPS"`
+	Burst             int     `yaml:"burst" toml:"burst" env:"RATE_LIMIT_BURST"`
+}
+
+type Events struct {
+	// Outbox dispatcher settings, see events.DispatcherOptions.
+	PollInterval time.Duration `yaml:"pollInterval" toml:"pollInterval" env:"EVENTS_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batchSize" toml:"batchSize" env:"EVENTS_BATCH_SIZE"`
+	MaxAttempts  int           `yaml:"maxAttempts" toml:"maxAttempts" env:"EVENTS_MAX_ATTEMPTS"`
+	RetryBackoff time.Duration `yaml:"retryBackoff" toml:"retryBackoff" env:"EVENTS_RETRY_BACKOFF"`
+	Lease        time.Duration `yaml:"lease" toml:"lease" env:"EVENTS_LEASE"`
+
+	// Delivery to a single hub subscriber.
+	DeliveryTimeout  time.Duration `yaml:"deliveryTimeout" toml:"deliveryTimeout" env:"EVENTS_DELIVERY_TIMEOUT"`
+	DeliveryAttempts int           `yaml:"deliveryAttempts" toml:"deliveryAttempts" env:"EVENTS_DELIVERY_ATTEMPTS"`
+	DeliveryBackoff  time.Duration `yaml:"deliveryBackoff" toml:"deliveryBackoff" env:"EVENTS_DELIVERY_BACKOFF"`
+	// CallbackNetworks are CIDRs hub callbacks may point into besides
+	// public addresses, such as the cluster network of in-house listeners.
+	CallbackNetworks []string `yaml:"callbackNetworks" toml:"callbackNetworks" env:"EVENTS_CALLBACK_NETWORKS"`
+
+	// QueueSize bounds the events waiting for delivery when the in-memory
+	// repository is used.
+	QueueSize int `yaml:"queueSize" toml:"queueSize" env:"EVENTS_QUEUE_SIZE"`
+}
+
+type Health struct {
+	// Timeout bounds each readiness check, such as the database ping.
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"HEALTH_TIMEOUT"`
+	// BacklogWarn is the number of undelivered events above which
+	// readiness reports a warning.
+	BacklogWarn int `yaml:"backlogWarn" toml:"backlogWarn" env:"HEALTH_BACKLOG_WARN"`
+}
+
+// Tracing exports OpenTelemetry spans.
+type Tracing struct {
+	// Exporter is "none", "otlp" for an OTLP/HTTP collector, "stdout", or
+	// "file" to write spans as JSON lines to File for local testing.
+	Exporter string `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	// Endpoint is the collector's host:port; when empty the standard
+	// OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	Insecure bool   `yaml:"insecure" toml:"insecure" env:"TRACING_OTLP_INSECURE"`
+	File     string `yaml:"file" toml:"file" env:"TRACING_FILE"`
+	// SampleRatio is the share of new traces recorded. Requests arriving
+	// with a traceparent follow the caller's sampling decision.
+	SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
 }
 
+// Default returns the configuration used for every setting no source
+// overrides. The database password has no default.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: Database{
+			Driver:          migrate.Postgres,
+			Path:            "tmf632.db",
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "postgres",
+			Name:            "tmf632db",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  time.Minute,
+			AutoMigrate:     true,
+		},
+		Repository: repository.KindDatabase,
+		API: API{
+			BasePath:        "/tmf-api/partyManagement/v4",
+			DefaultPageSize: 10,
+			MaxPageSize:     1000,
+			IDStrategy:      ids.StrategyUUID,
+			IdempotencyTTL:  24 * time.Hour,
+			RequireIfMatch:  true,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Auth: Auth{
+			JWKSRefresh: 15 * time.Minute,
+		},
+		Tenancy: Tenancy{
+			Claim:  "tenant",
+			Header: "X-Tenant-ID",
+		},
+		CORS: CORS{
+			AllowOrigins: []string{"*"},
+		},
+		RateLimit: RateLimit{
+			RequestsPerSecond: 50,
+			Burst:             100,
+		},
+		Events: Events{
+			PollInterval:     time.Second,
+			BatchSize:        50,
+			MaxAttempts:      10,
+			RetryBackoff:     5 * time.Second,
+			Lease:            5 * time.Minute,
+			DeliveryTimeout:  10 * time.Second,
+			DeliveryAttempts: 3,
+			DeliveryBackoff:  200 * time.Millisecond,
+			QueueSize:        1024,
+		},
+		Health: Health{
+			Timeout:     2 * time.Second,
+			BacklogWarn: 1000,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			File:        "traces.jsonl",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load resolves the configuration from all sources and validates it.
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	godotenv.Load()
 
-	return &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "password"),
-		DBName:     getEnv("DB_NAME", "tmf632db"),
-		ServerPort: getEnv("SERVER_PORT", "8080"),
-	}, nil
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, fmt.Errorf("CONFIG_FILE: %w", err)
+		}
+	}
+	if err := loadEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Validate reports every invalid or missing setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port != "", "server.port (SERVER_PORT) is required")
+	check(c.Server.ReadTimeout >= 0 && c.Server.ReadHeaderTimeout >= 0 && c.Server.WriteTimeout >= 0 && c.Server.IdleTimeout >= 0,
+		"server timeouts must not be negative")
+	check(c.Server.DrainDelay >= 0, "server.drainDelay (SERVER_DRAIN_DELAY) must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout (SERVER_SHUTDOWN_TIMEOUT) must be positive")
+	check(c.Server.TLS.CertFile != "" || c.Server.TLS.KeyFile == "", "server.tls.certFile (SERVER_TLS_CERT) is required with a key file")
+	check(c.Server.TLS.KeyFile != "" || c.Server.TLS.CertFile == "", "server.tls.keyFile (SERVER_TLS_KEY) is required with a certificate file")
+
+	check(c.Repository == repository.KindDatabase || c.Repository == repository.KindMemory,
+		"repository (REPOSITORY) must be %s or %s, got %q", repository.KindDatabase, repository.KindMemory, c.Repository)
+	if c.Repository == repository.KindDatabase {
+		db := c.Database
+		check(db.ConnectTimeout >= 0, "database.connectTimeout (DB_CONNECT_TIMEOUT) must not be negative")
+		switch db.Driver {
+		case migrate.Postgres:
+			check(db.Host != "", "database.host (DB_HOST) is required")
+			check(db.Port != "", "database.port (DB_PORT) is required")
+			check(db.User != "", "database.user (DB_USER) is required")
+			check(db.Password != "", "database.password (DB_PASSWORD or DB_PASSWORD_FILE) is required")
+			check(db.Name != "", "database.name (DB_NAME) is required")
+			check(sslModes[db.SSLMode], "database.sslMode (DB_SSLMODE) %q is not a libpq sslmode", db.SSLMode)
+		case migrate.SQLite:
+			check(db.Path != "", "database.path (DB_PATH) is required")
+		default:
+			check(false, "database.driver (DB_DRIVER) must be %s or %s, got %q", migrate.Postgres, migrate.SQLite, db.Driver)
+		}
+		check(db.MaxOpenConns >= 0 && db.MaxIdleConns >= 0, "database connection pool sizes must not be negative")
+		check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns,
+			"database.maxIdleConns (%d) must not exceed database.maxOpenConns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	}
+
+	check(c.API.DefaultPageSize >= 1 && c.API.MaxPageSize >= c.API.DefaultPageSize,
+		"page sizes must satisfy 1 <= DEFAULT_PAGE_SIZE (%d) <= MAX_PAGE_SIZE (%d)", c.API.DefaultPageSize, c.API.MaxPageSize)
+	if _, err := ids.New(c.API.IDStrategy); err != nil {
+		errs = append(errs, fmt.Errorf("api.idStrategy (ID_STRATEGY): %w", err))
 	}
-	return fallback
+	check(c.API.IdempotencyTTL > 0, "api.idempotencyTTL (IDEMPOTENCY_TTL) must be positive, got %s", c.API.IdempotencyTTL)
+
+	check(logLevels[c.Log.Level], "log.level (LOG_LEVEL) must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "console", "log.format (LOG_FORMAT) must be json or console, got %q", c.Log.Format)
+
+	if c.Auth.Enabled {
+		check(c.Auth.Issuer != "", "auth.issuer (AUTH_ISSUER) is required when auth is enabled")
+		check(len(c.Auth.Audiences) > 0, "auth.audiences (AUTH_AUDIENCES) is required when auth is enabled")
+		check(c.Auth.JWKSURL == "" || c.Auth.JWKSFile == "",
+			"set only one of auth.jwksURL (AUTH_JWKS_URL) and auth.jwksFile (AUTH_JWKS_FILE)")
+		check(c.Auth.JWKSURL != "" || c.Auth.JWKSFile != "" || c.Auth.IntrospectionURL != "",
+			"auth.jwksURL (AUTH_JWKS_URL), auth.jwksFile (AUTH_JWKS_FILE) or auth.introspectionURL (AUTH_INTROSPECTION_URL) is required when auth is enabled")
+		check(c.Auth.JWKSRefresh > 0, "auth.jwksRefresh (AUTH_JWKS_REFRESH) must be positive")
+	}
+
+	if c.Tenancy.Enabled {
+		check(c.Repository == repository.KindDatabase, "tenancy (TENANCY_ENABLED) requires the database repository")
+		check(c.Tenancy.Header != "" || (c.Auth.Enabled && c.Tenancy.Claim != ""),
+			"tenancy.header (TENANCY_HEADER), or auth with tenancy.claim (TENANCY_CLAIM), is required when tenancy is enabled")
+	}
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RequestsPerSecond > 0, "rateLimit.requestsPerSecond (RATE_LIMIT_RPS) must be positive")
+		check(c.RateLimit.Burst >= 1, "rateLimit.burst (RATE_LIMIT_BURST) must be at least 1")
+	}
+
+	ev := c.Events
+	check(ev.PollInterval > 0 && ev.RetryBackoff > 0 && ev.Lease > 0 && ev.DeliveryTimeout > 0 && ev.DeliveryBackoff > 0,
+		"event delivery intervals must be positive")
+	check(ev.BatchSize >= 1 && ev.MaxAttempts >= 1 && ev.DeliveryAttempts >= 1 && ev.QueueSize >= 1,
+		"event batch size, attempts and queue size must be at least 1")
+	for _, network := range ev.CallbackNetworks {
+		_, err := netip.ParsePrefix(network)
+		check(err == nil, "events.callbackNetworks (EVENTS_CALLBACK_NETWORKS) must be CIDRs, got %q", network)
+	}
+
+	check(c.Health.Timeout > 0, "health.timeout (HEALTH_TIMEOUT) must be positive")
+	check(c.Health.BacklogWarn >= 1, "health.backlogWarn (HEALTH_BACKLOG_WARN) must be at least 1")
+
+	check(tracingExporters[c.Tracing.Exporter],
+		"tracing.exporter (TRACING_EXPORTER) must be none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file (TRACING_FILE) is required with the file exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sampleRatio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	return errors.Join(errs...)
+}
+
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+var logLevels = map[string]bool{
+	"debug": true, "info": true, "warn": true, "error": true,
+}
+
+var tracingExporters = map[string]bool{
+	"none": true, "otlp": true, "stdout": true, "file": true,
 }
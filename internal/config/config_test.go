@@ -0,0 +1,126 @@
+// internal/config/config_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valid returns the defaults completed with the one setting they lack.
+func valid() *Config {
+	cfg := Default()
+	cfg.Database.Password = "secret"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+
+	tests := []struct {
+		change func(*Config)
+		want   []string
+	}{
+		{func(c *Config) { c.Database.Password = "" }, []string{"DB_PASSWORD"}},
+		{func(c *Config) { c.Database.Driver = "mysql" }, []string{"DB_DRIVER"}},
+		{func(c *Config) { c.Database.Driver, c.Database.Path = "sqlite", "" }, []string{"DB_PATH"}},
+		{func(c *Config) { c.Database.MaxOpenConns, c.Database.MaxIdleConns = 5, 10 }, []string{"maxIdleConns"}},
+		{func(c *Config) { c.API.DefaultPageSize = 2000 }, []string{"MAX_PAGE_SIZE"}},
+		{func(c *Config) { c.API.IDStrategy = "sequence" }, []string{"ID_STRATEGY"}},
+		{func(c *Config) { c.Server.TLS.KeyFile = "tls.key" }, []string{"SERVER_TLS_CERT"}},
+		{func(c *Config) { c.Auth.Enabled = true }, []string{"AUTH_ISSUER", "AUTH_AUDIENCES", "AUTH_JWKS_URL"}},
+		{func(c *Config) { c.Tenancy.Enabled, c.Repository = true, "memory" }, []string{"requires the database repository"}},
+		{func(c *Config) { c.Events.CallbackNetworks = []string{"10.0.0.1"} }, []string{"EVENTS_CALLBACK_NETWORKS"}},
+		{func(c *Config) { c.Events.Lease = 0 }, []string{"intervals must be positive"}},
+		{func(c *Config) { c.Tracing.SampleRatio = 2 }, []string{"TRACING_SAMPLE_RATIO"}},
+		// Every problem is reported at once
+		{func(c *Config) { c.Log.Level, c.Log.Format = "trace", "xml" }, []string{"LOG_LEVEL", "LOG_FORMAT"}},
+		// Settings of a disabled feature are not checked
+		{func(c *Config) { c.Repository, c.Database.Password = "memory", "" }, nil},
+	}
+	for i, tt := range tests {
+		cfg := valid()
+		tt.change(cfg)
+		err := cfg.Validate()
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("case %d: %v", i, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("case %d: no error, want %q", i, tt.want)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("case %d: %q does not mention %s", i, err, want)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	password := filepath.Join(dir, "password")
+	if err := os.WriteFile(password, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The example file loads as it is; the environment overrides it
+	t.Setenv("CONFIG_FILE", "../../config.example.yaml")
+	t.Setenv("DB_PASSWORD_FILE", password)
+	t.Setenv("DB_PORT", "6543")
+	t.Setenv("EVENTS_LEASE", "90s")
+	t.Setenv("AUTH_AUDIENCES", "a, b")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Password != "from-file" || cfg.Database.Port != "6543" || cfg.Events.Lease != 90*time.Second {
+		t.Errorf("got password %q, port %q, lease %s", cfg.Database.Password, cfg.Database.Port, cfg.Events.Lease)
+	}
+	if len(cfg.Auth.Audiences) != 2 || cfg.Auth.Audiences[1] != "b" {
+		t.Errorf("got audiences %q", cfg.Auth.Audiences)
+	}
+	if redacted := cfg.Redacted(); redacted.Database.Password != "REDACTED" || cfg.Database.Password != "from-file" {
+		t.Errorf("redacted %q, original %q", redacted.Database.Password, cfg.Database.Password)
+	}
+
+	t.Setenv("DB_PASSWORD", "from-env")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Errorf("password and password file: got %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content string
+		ok            bool
+	}{
+		{"config.yaml", "server:\n  port: \"9090\"\n", true},
+		{"config.toml", "[server]\nport = \"9090\"\n", true},
+		{"typo.yaml", "server:\n  prot: \"9090\"\n", false},
+		{"typo.toml", "[server]\nprot = \"9090\"\n", false},
+		{"config.json", "{}", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg := Default()
+		err := loadFile(path, cfg)
+		if tt.ok && (err != nil || cfg.Server.Port != "9090" || cfg.Database.Port != "5432") {
+			t.Errorf("%s: got %v, port %s", tt.name, err, cfg.Server.Port)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: loaded", tt.name)
+		}
+	}
+}
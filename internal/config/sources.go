@@ -0,0 +1,157 @@
+// internal/config/sources.go
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile merges the YAML or TOML file at path into cfg, chosen by its
+// extension. Keys the file leaves out keep their current value; unknown
+// keys are rejected so typos do not go unnoticed.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// loadEnv overrides cfg with the environment variables named by the env
+// tags, and secrets with the contents of the matching _FILE variable.
+func loadEnv(cfg *Config) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, v reflect.Value) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+
+		raw, set := os.LookupEnv(name)
+		if field.Tag.Get("secret") == "true" {
+			if path, ok := os.LookupEnv(name + "_FILE"); ok {
+				if set {
+					errs = append(errs, fmt.Errorf("set either %s or %s_FILE, not both", name, name))
+					return
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s_FILE: %w", name, err))
+					return
+				}
+				// Secret files conventionally end with a newline
+				raw, set = strings.TrimRight(string(data), "\r\n"), true
+			}
+		}
+		if !set {
+			return
+		}
+
+		if err := setValue(v, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue parses raw into v according to its type. Lists are comma
+// separated.
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 24h: %w", err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be an integer: %w", err)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number: %w", err)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean: %w", err)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// walk calls fn for every leaf setting of the struct v.
+func walk(v reflect.Value, fn func(field reflect.StructField, v reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field, fv := v.Type().Field(i), v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			walk(fv, fn)
+			continue
+		}
+		fn(field, fv)
+	}
+}
+
+// Redacted returns a copy of c with every secret replaced, for display.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	walk(reflect.ValueOf(&redacted).Elem(), func(field reflect.StructField, v reflect.Value) {
+		if field.Tag.Get("secret") == "true" && v.String() != "" {
+			v.SetString("REDACTED")
+		}
+	})
+	return &redacted
+}
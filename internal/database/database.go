@@ -4,35 +4,69 @@ package database
 import (
 	"fmt"
 
+	"github.com/your-username/tmf632-service/db/migrations"
 	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/migrate"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func Initialize(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+// Initialize opens the database selected by cfg.Driver and sizes its
+// connection pool. The schema is managed by the versioned migrations in
+// db/migrations, see NewMigrator.
+func Initialize(cfg config.Database) (*gorm.DB, error) {
+	gormConfig := &gorm.Config{
+		// Map constraint violations to gorm.ErrDuplicatedKey and
+		// gorm.ErrForeignKeyViolated so handlers can report conflicts
+		TranslateError: true,
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	var db *gorm.DB
+	var err error
+	switch cfg.Driver {
+	case migrate.Postgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+		db, err = gorm.Open(postgres.Open(dsn), gormConfig)
+	case migrate.SQLite:
+		db, err = openSQLite(cfg.Path, gormConfig)
+	default:
+		err = fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Auto-migrate the schemas
-	err = autoMigrate(db)
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.Driver == migrate.SQLite {
+		// Every SQLite transaction takes the file's only write lock when it
+		// begins, so further connections would just queue on it; one
+		// connection makes them queue in the pool instead
+		sqlDB.SetMaxOpenConns(1)
+	}
 
 	return db, nil
 }
 
-func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&models.Individual{},
-		&models.ContactMedium{},
-		&models.ExternalReference{},
-		&models.IndividualIdentification{},
-		&models.PartyCharacteristic{},
-	)
+// NewMigrator returns a Migrator for the embedded schema migrations of the
+// driver db was opened with.
+func NewMigrator(db *gorm.DB, logger *zap.SugaredLogger) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	dialect := db.Dialector.Name()
+	fsys, err := migrations.FS(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, fsys, dialect, logger)
 }
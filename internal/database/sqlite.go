@@ -0,0 +1,131 @@
+// internal/database/sqlite.go
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sqliteDriverName is the database/sql driver SQLite files are opened
+// with, see registerSQLite.
+const sqliteDriverName = "tmf632-sqlite"
+
+var registerOnce sync.Once
+
+// openSQLite opens the SQLite file at path so that it behaves like the
+// Postgres database as far as the repository can tell: foreign keys are
+// enforced, REGEXP filters work and timestamps compare correctly.
+func openSQLite(path string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	registerOnce.Do(registerSQLite)
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	// Wait for the write lock held by another process, such as a second
+	// server on the same file, instead of failing with SQLITE_BUSY
+	params.Add("_pragma", "busy_timeout(5000)")
+	// Every transaction takes the write lock when it begins, which gives
+	// the read-then-write transactions the isolation SELECT ... FOR UPDATE
+	// gives them on Postgres
+	params.Set("_txlock", "immediate")
+
+	db, err := gorm.Open(&sqlite.Dialector{
+		DriverName: sqliteDriverName,
+		DSN:        path + "?" + params.Encode(),
+	}, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has no row locks; drop FOR UPDATE [SKIP LOCKED] rather than
+	// fail on it
+	db.ClauseBuilders[clause.Locking{}.Name()] = func(clause.Clause, clause.Builder) {}
+	return db, nil
+}
+
+// registerSQLite registers sqliteDriverName and the regexp function that
+// backs the REGEXP operator.
+func registerSQLite() {
+	gosqlite.MustRegisterDeterministicScalarFunction("regexp", 2, sqliteRegexp)
+
+	// sql.Open only looks the driver up, it does not connect
+	base, err := sql.Open(sqlite.DriverName, "")
+	if err != nil {
+		panic(err)
+	}
+	sql.Register(sqliteDriverName, utcDriver{base.Driver()})
+	base.Close()
+}
+
+// sqliteRegexp implements "value REGEXP pattern", which SQLite evaluates as
+// regexp(pattern, value). A NULL value never matches.
+func sqliteRegexp(_ *gosqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexp pattern must be text, got %T", args[0])
+	}
+	value, ok := args[1].(string)
+	if !ok {
+		return nil, nil
+	}
+	return regexp.MatchString(pattern, value)
+}
+
+// utcDriver wraps the SQLite driver so every time is written in UTC.
+// SQLite stores times as text and compares them as text, which is only
+// correct when they all share one offset.
+type utcDriver struct {
+	driver.Driver
+}
+
+// sqliteConn is the set of interfaces the wrapped driver's connections
+// implement; utcConn must keep exposing them.
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+func (d utcDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := conn.(sqliteConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("sqlite connection %T lacks context support", conn)
+	}
+	return utcConn{c}, nil
+}
+
+type utcConn struct {
+	sqliteConn
+}
+
+// CheckNamedValue converts arguments as database/sql would by default,
+// then moves times to UTC.
+func (utcConn) CheckNamedValue(nv *driver.NamedValue) error {
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := v.(time.Time); ok {
+		v = t.UTC()
+	}
+	nv.Value = v
+	return nil
+}
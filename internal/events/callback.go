@@ -0,0 +1,115 @@
+// internal/events/callback.go
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrCallbackNotAllowed is returned for a callback that is not an absolute
+// http(s) URL, or whose host is not a public address.
+var ErrCallbackNotAllowed = errors.New("callback not allowed")
+
+// nonPublic lists the special-purpose ranges netip has no predicate for.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// CallbackGuard keeps hub subscribers from pointing the dispatcher at the
+// service's own network: callbacks must reach public addresses, or ones in
+// the networks it was built with. Callbacks are checked when registered and
+// again on every connection, so a host cannot resolve elsewhere later.
+type CallbackGuard struct {
+	allowed []netip.Prefix
+}
+
+// NewCallbackGuard returns a guard that also admits addresses in networks,
+// given in CIDR notation.
+func NewCallbackGuard(networks []string) (*CallbackGuard, error) {
+	g := &CallbackGuard{}
+	for _, network := range networks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, err
+		}
+		g.allowed = append(g.allowed, prefix.Masked())
+	}
+	return g, nil
+}
+
+// Allowed reports whether callbacks may connect to addr.
+func (g *CallbackGuard) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check reports ErrCallbackNotAllowed unless callback is an absolute
+// http(s) URL whose host resolves only to allowed addresses.
+func (g *CallbackGuard) Check(ctx context.Context, callback string) error {
+	u, err := url.Parse(callback)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: must be an absolute http(s) URL", ErrCallbackNotAllowed)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: host %s does not resolve", ErrCallbackNotAllowed, u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !g.Allowed(addr) {
+			return fmt.Errorf("%w: host %s is not a public address", ErrCallbackNotAllowed, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// Transport returns an HTTP transport that refuses connections to
+// addresses the guard does not allow, whatever the callback resolved to
+// when it was registered. Proxies are not used, as they would connect on
+// the guard's behalf.
+func (g *CallbackGuard) Transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !g.Allowed(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s is not a public address", ErrCallbackNotAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
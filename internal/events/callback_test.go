@@ -0,0 +1,85 @@
+// internal/events/callback_test.go
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	guard, err := NewCallbackGuard([]string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"10.2.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		// Admitted by the configured network
+		{"10.1.2.3", true},
+	}
+	for _, tt := range tests {
+		if got := guard.Allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	if _, err := NewCallbackGuard([]string{"10.1.0.0"}); err == nil {
+		t.Error("network without a prefix length was accepted")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	guard, _ := NewCallbackGuard(nil)
+	tests := []struct {
+		callback string
+		allowed  bool
+	}{
+		{"https://8.8.8.8/listener", true},
+		{"http://[2001:4860:4860::8888]:8080/listener", true},
+		{"http://127.0.0.1/listener", false},
+		{"http://[::1]/listener", false},
+		{"ftp://8.8.8.8/listener", false},
+		{"/listener", false},
+		{"http:///listener", false},
+	}
+	for _, tt := range tests {
+		err := guard.Check(context.Background(), tt.callback)
+		if tt.allowed && err != nil || !tt.allowed && !errors.Is(err, ErrCallbackNotAllowed) {
+			t.Errorf("%s: got %v", tt.callback, err)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	guard, _ := NewCallbackGuard(nil)
+	_, err := (&http.Client{Transport: guard.Transport()}).Get(srv.URL)
+	if !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Errorf("loopback: got %v, want ErrCallbackNotAllowed", err)
+	}
+
+	guard, _ = NewCallbackGuard([]string{"127.0.0.0/8"})
+	resp, err := (&http.Client{Transport: guard.Transport()}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("allowed network: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,42 @@
+// internal/events/event.go
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TMF632 Individual event types.
+const (
+	IndividualCreateEvent               = "IndividualCreateEvent"
+	IndividualAttributeValueChangeEvent = "IndividualAttributeValueChangeEvent"
+	IndividualStateChangeEvent          = "IndividualStateChangeEvent"
+	IndividualDeleteEvent               = "IndividualDeleteEvent"
+)
+
+// Event is the TMF688 notification envelope POSTed to hub subscribers.
+// Payload is keyed by resource name, e.g. {"individual": {...}}.
+type Event struct {
+	EventID   string                 `json:"eventId"`
+	EventTime time.Time              `json:"eventTime"`
+	EventType string                 `json:"eventType"`
+	Payload   map[string]interface{} `json:"event"`
+}
+
+// New builds an event of eventType carrying resource under resourceName.
+func New(eventType, resourceName string, resource interface{}) Event {
+	return Event{
+		EventID:   uuid.NewString(),
+		EventTime: time.Now().UTC(),
+		EventType: eventType,
+		Payload:   map[string]interface{}{resourceName: resource},
+	}
+}
+
+// Publisher records events as part of the caller's transaction, so an event
+// exists if and only if the change that produced it was committed.
+type Publisher interface {
+	Publish(tx *gorm.DB, evt Event) error
+}
@@ -0,0 +1,257 @@
+// internal/events/hub.go
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/masking"
+	"github.com/your-username/tmf632-service/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// errPermanent marks a delivery failure that retrying cannot fix, such as a
+// 404 from the subscriber.
+var errPermanent = errors.New("permanent delivery failure")
+
+// HubSink POSTs events to the callbacks registered through /hub whose query
+// matches the event. It retries briefly in-process; longer outages are
+// covered by the outbox retrying the event for the subscribers it has not
+// reached yet.
+type HubSink struct {
+	Subscriptions Subscriptions
+	Client        *http.Client
+	Logger        *zap.SugaredLogger
+
+	// MaxAttempts bounds deliveries per subscriber; Backoff is the delay
+	// before the first retry and doubles after each failure.
+	MaxAttempts int
+	Backoff     time.Duration
+
+	// Observe, when set, is called with the outcome of every delivery to
+	// a subscriber, after any retries.
+	Observe func(evt Event, err error)
+
+	// Masking hides or masks the attributes of the resource in an event
+	// that the roles of the subscription may not see.
+	Masking masking.Policy
+}
+
+// Subscriptions lists the hub registrations events are matched against.
+type Subscriptions interface {
+	ListSubscriptions(ctx context.Context) ([]models.EventSubscription, error)
+}
+
+// NewHubSink returns a HubSink that only delivers to public addresses; see
+// CallbackGuard.
+func NewHubSink(subscriptions Subscriptions, logger *zap.SugaredLogger) *HubSink {
+	guard, _ := NewCallbackGuard(nil)
+	return &HubSink{
+		Subscriptions: subscriptions,
+		Client:        &http.Client{Timeout: 10 * time.Second, Transport: guard.Transport()},
+		Logger:        logger,
+		MaxAttempts:   3,
+		Backoff:       200 * time.Millisecond,
+	}
+}
+
+// Deliver sends evt to every matching subscriber. A failing subscriber does
+// not prevent delivery to the others; all failures are returned joined.
+// Subscribers the outbox has already settled for evt are skipped, and those
+// delivered to or failed permanently are added to its set.
+func (s *HubSink) Deliver(ctx context.Context, evt Event) error {
+	subscriptions, err := s.Subscriptions.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	settled := deliveredFrom(ctx)
+	var errs []error
+	for _, sub := range subscriptions {
+		if settled[sub.ID] {
+			continue
+		}
+		// Queries see the event as the subscriber does, so they cannot
+		// probe attributes hidden from it
+		shaped, err := s.shape(evt, strings.Fields(sub.Roles))
+		if err != nil {
+			return err
+		}
+		matched, err := Matches(sub.Query, shaped)
+		if err != nil {
+			// The query will not parse on a retry either
+			errs = append(errs, fmt.Errorf("subscription %s: %w: %v", sub.ID, errPermanent, err))
+			settled.add(sub.ID)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		body, err := json.Marshal(shaped)
+		if err != nil {
+			return err
+		}
+		err = s.post(ctx, sub.Callback, body)
+		if s.Observe != nil {
+			s.Observe(evt, err)
+		}
+		if err == nil || errors.Is(err, errPermanent) {
+			settled.add(sub.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subscription %s: %w", sub.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shape returns evt as seen by a subscriber with roles.
+func (s *HubSink) shape(evt Event, roles []string) (Event, error) {
+	shaped := evt
+	shaped.Payload = make(map[string]interface{}, len(evt.Payload))
+	for name, resource := range evt.Payload {
+		var err error
+		if shaped.Payload[name], err = s.Masking.Shape(name, roles, resource); err != nil {
+			return evt, err
+		}
+	}
+	return shaped, nil
+}
+
+func (s *HubSink) post(ctx context.Context, callback string, body []byte) error {
+	delay := s.Backoff
+	var lastErr error
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		lastErr = s.postOnce(ctx, callback, body)
+		if lastErr == nil || errors.Is(lastErr, errPermanent) {
+			return lastErr
+		}
+
+		s.Logger.Warnw("Event delivery attempt failed",
+			"callback", callback,
+			"attempt", attempt,
+			"error", lastErr)
+
+		if attempt == s.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return lastErr
+}
+
+func (s *HubSink) postOnce(ctx context.Context, callback string, body []byte) (err error) {
+	ctx, span := tracer.Start(ctx, "POST",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethod(http.MethodPost), semconv.HTTPURL(callback)),
+	)
+	defer func() {
+		endDelivery(span, err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Subscribers see the traceparent of the delivery span
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := s.Client.Do(req)
+	if errors.Is(err, ErrCallbackNotAllowed) {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("callback returned %d", resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return fmt.Errorf("%w: callback returned %d", errPermanent, resp.StatusCode)
+	}
+	return fmt.Errorf("callback returned %d", resp.StatusCode)
+}
+
+// Matches reports whether evt satisfies a hub query such as
+// "eventType=IndividualCreateEvent,IndividualDeleteEvent" or
+// "event.individual.familyName=Smith". Every key must match one of its
+// comma separated values; an empty query matches every event.
+func Matches(query string, evt Event) (bool, error) {
+	if strings.TrimSpace(query) == "" {
+		return true, nil
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return false, fmt.Errorf("invalid query %q: %w", query, err)
+	}
+
+	raw, err := json.Marshal(evt)
+	if err != nil {
+		return false, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return false, err
+	}
+
+	for key, values := range params {
+		actual, ok := lookup(doc, strings.Split(key, "."))
+		if !ok {
+			return false, nil
+		}
+		if !anyEqual(actual, values) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func lookup(doc interface{}, path []string) (interface{}, bool) {
+	for _, segment := range path {
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = m[segment]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func anyEqual(actual interface{}, values []string) bool {
+	s := fmt.Sprint(actual)
+	for _, v := range values {
+		for _, candidate := range strings.Split(v, ",") {
+			if candidate == s {
+				return true
+			}
+		}
+	}
+	return false
+}
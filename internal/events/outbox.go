@@ -0,0 +1,383 @@
+// internal/events/outbox.go
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sink delivers an event to its destination. Deliver may retry internally
+// and should honour ctx cancellation.
+type Sink interface {
+	Deliver(ctx context.Context, evt Event) error
+}
+
+// Outbox is the transactional Publisher: events are written to the
+// outbox_events table through the caller's transaction.
+type Outbox struct {
+	wake chan struct{}
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{wake: make(chan struct{}, 1)}
+}
+
+// Publish stores evt using tx. The row only becomes visible to the
+// dispatcher once tx commits; a rollback discards it with the change.
+func (o *Outbox) Publish(tx *gorm.DB, evt Event) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	row := models.OutboxEvent{
+		EventID:       evt.EventID,
+		EventType:     evt.EventType,
+		Payload:       string(payload),
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+		TraceParent:   traceParent(tx.Statement.Context),
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return err
+	}
+
+	// Nudge the dispatcher; if the transaction has not committed yet the
+	// next poll picks the row up instead.
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// DispatcherOptions tune outbox delivery.
+type DispatcherOptions struct {
+	// PollInterval is how often the outbox is scanned for due events.
+	PollInterval time.Duration
+	// BatchSize is the number of events claimed per transaction.
+	BatchSize int
+	// MaxAttempts is the number of deliveries before an event is marked
+	// failed and no longer retried. Retries only go to the subscribers the
+	// event has not reached, and an event whose every failure is permanent,
+	// such as a 404 from the callback, is marked failed at once.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry; it doubles with
+	// every further attempt.
+	RetryBackoff time.Duration
+	// Lease is how long claimed events are reserved for delivery. Events
+	// not delivered by then, say because the dispatcher crashed, are
+	// claimed again.
+	Lease time.Duration
+}
+
+// DefaultDispatcherOptions are used for any zero field of the options
+// passed to NewDispatcher.
+var DefaultDispatcherOptions = DispatcherOptions{
+	PollInterval: time.Second,
+	BatchSize:    50,
+	MaxAttempts:  10,
+	RetryBackoff: 5 * time.Second,
+	Lease:        5 * time.Minute,
+}
+
+// Dispatcher delivers committed outbox events to its sinks. Rows are
+// claimed with FOR UPDATE SKIP LOCKED in a short transaction that leases
+// them by pushing their next attempt out, so any number of replicas can run
+// a dispatcher against the same database without delivering an event twice
+// concurrently, and no lock is held while sinks deliver. A crash before the
+// row is marked sent lets the lease run out and the event is delivered
+// again: delivery is at-least-once.
+type Dispatcher struct {
+	db     *gorm.DB
+	outbox *Outbox
+	sinks  []Sink
+	opts   DispatcherOptions
+	logger *zap.SugaredLogger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(db *gorm.DB, outbox *Outbox, logger *zap.SugaredLogger, opts DispatcherOptions, sinks ...Sink) *Dispatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultDispatcherOptions.PollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultDispatcherOptions.BatchSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultDispatcherOptions.MaxAttempts
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultDispatcherOptions.RetryBackoff
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = DefaultDispatcherOptions.Lease
+	}
+	return &Dispatcher{
+		db:     db,
+		outbox: outbox,
+		sinks:  sinks,
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Start launches the polling loop.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.wg.Add(1)
+	go d.run(ctx)
+}
+
+// Stop cancels the polling loop and waits for the current batch to finish.
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// Backlog counts the events still waiting for delivery, including those
+// waiting for a retry.
+func (d *Dispatcher) Backlog(ctx context.Context) (int64, error) {
+	var n int64
+	err := d.db.WithContext(tenant.System(ctx)).Model(&models.OutboxEvent{}).
+		Where("status = ?", models.OutboxPending).
+		Count(&n).Error
+	return n, err
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.outbox.wake:
+		}
+
+		// Drain everything that is due before waiting again.
+		for {
+			n, err := d.DispatchBatch(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				d.logger.Errorw("Failed to dispatch outbox events", "error", err)
+			}
+			if err != nil || n < d.opts.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// DispatchBatch claims up to BatchSize due events, delivers them and
+// records each outcome. It returns the number of events claimed, from
+// every tenant.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	rows, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range rows {
+		if err := d.deliver(ctx, &rows[i]); err != nil {
+			if ctx.Err() != nil {
+				// Shutting down: hand the rest back so another replica
+				// retries them without waiting for the lease
+				if err := d.release(ctx, rows[i:]); err != nil {
+					d.logger.Warnw("Failed to release outbox events", "error", err)
+				}
+			}
+			return len(rows), err
+		}
+	}
+	return len(rows), nil
+}
+
+// claim leases up to BatchSize due events. Each claim counts as an
+// attempt, so an event that keeps crashing the dispatcher is eventually
+// given up on, and the attempt count tells a later claim of the same event
+// from this one.
+func (d *Dispatcher) claim(ctx context.Context) ([]models.OutboxEvent, error) {
+	var rows []models.OutboxEvent
+	err := d.db.WithContext(tenant.System(ctx)).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, now).
+			Order("id").
+			Limit(d.opts.BatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+			rows[i].Attempts++
+			rows[i].NextAttemptAt = now.Add(d.opts.Lease)
+		}
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(d.opts.Lease),
+			}).Error
+	})
+	return rows, err
+}
+
+// release returns claimed events for immediate retry, undoing the attempt
+// their claim counted.
+func (d *Dispatcher) release(ctx context.Context, rows []models.OutboxEvent) error {
+	db := d.db.WithContext(tenant.System(context.WithoutCancel(ctx)))
+	for _, row := range rows {
+		if err := db.Model(&models.OutboxEvent{ID: row.ID}).
+			Where("attempts = ?", row.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        row.Attempts - 1,
+				"next_attempt_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, row *models.OutboxEvent) error {
+	ctx, span := startDelivery(withTraceParent(ctx, row.TraceParent), row.EventID, row.EventType)
+	defer span.End()
+
+	// Sinks only see the subscriptions of the event's own tenant, and skip
+	// those earlier attempts settled
+	settled := newDelivered(row.Delivered)
+	tenantCtx := withDelivered(tenant.NewContext(ctx, row.TenantID), settled)
+
+	var evt Event
+	deliveryErr := json.Unmarshal([]byte(row.Payload), &evt)
+	if deliveryErr != nil {
+		deliveryErr = fmt.Errorf("%w: %v", errPermanent, deliveryErr)
+	} else {
+		var errs []error
+		for _, sink := range d.sinks {
+			if err := sink.Deliver(tenantCtx, evt); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		deliveryErr = errors.Join(errs...)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	endDelivery(span, deliveryErr)
+	row.Delivered = settled.String()
+
+	if deliveryErr == nil {
+		now := time.Now()
+		row.Status = models.OutboxSent
+		row.SentAt = &now
+		row.LastError = ""
+	} else {
+		giveUp := permanent(deliveryErr)
+		d.logger.Warnw("Outbox event delivery failed",
+			"eventId", row.EventID,
+			"eventType", row.EventType,
+			"attempt", row.Attempts,
+			"permanent", giveUp,
+			"error", deliveryErr)
+
+		row.LastError = deliveryErr.Error()
+		if giveUp || row.Attempts >= d.opts.MaxAttempts {
+			row.Status = models.OutboxFailed
+		} else {
+			row.NextAttemptAt = time.Now().Add(d.opts.RetryBackoff << (row.Attempts - 1))
+		}
+	}
+
+	// The outcome is dropped if the lease ran out and the event was
+	// claimed again meanwhile; that claim delivers and records it
+	return d.db.WithContext(tenant.System(ctx)).Model(row).
+		Where("attempts = ?", row.Attempts).
+		Select("status", "last_error", "next_attempt_at", "sent_at", "delivered").
+		Updates(row).Error
+}
+
+// permanent reports whether every failure joined in err is permanent, so
+// retrying the event cannot reach any further subscriber. Sinks join one
+// error per subscriber.
+func permanent(err error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return errors.Is(err, errPermanent)
+	}
+	for _, err := range joined.Unwrap() {
+		if !permanent(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// delivered is the set of subscriptions an event is settled for: delivered
+// to, or failed permanently. The outbox keeps it on the row so a retry only
+// reaches the subscribers still owed the event.
+type delivered map[string]bool
+
+type deliveredKey struct{}
+
+// newDelivered parses the space separated ids stored on an outbox row.
+func newDelivered(ids string) delivered {
+	set := delivered{}
+	for _, id := range strings.Fields(ids) {
+		set[id] = true
+	}
+	return set
+}
+
+// withDelivered returns ctx carrying set for sinks to consult and extend.
+func withDelivered(ctx context.Context, set delivered) context.Context {
+	return context.WithValue(ctx, deliveredKey{}, set)
+}
+
+// deliveredFrom returns the set in ctx, or nil when deliveries are not
+// tracked, as with the Relay.
+func deliveredFrom(ctx context.Context) delivered {
+	set, _ := ctx.Value(deliveredKey{}).(delivered)
+	return set
+}
+
+// add settles id; it does nothing on a nil set.
+func (d delivered) add(id string) {
+	if d != nil {
+		d[id] = true
+	}
+}
+
+// String returns the ids sorted and separated by spaces.
+func (d delivered) String() string {
+	ids := make([]string, 0, len(d))
+	for id := range d {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, " ")
+}
@@ -0,0 +1,242 @@
+// internal/events/outbox_test.go
+package events_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/database"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// subscriptions is a fixed list of hub registrations.
+type subscriptions []models.EventSubscription
+
+func (s subscriptions) ListSubscriptions(context.Context) ([]models.EventSubscription, error) {
+	return s, nil
+}
+
+// callback is a subscriber answering with the next of its statuses, and
+// then with the last one.
+type callback struct {
+	*httptest.Server
+	calls atomic.Int32
+}
+
+func newCallback(t *testing.T, statuses ...int) *callback {
+	cb := &callback{}
+	cb.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(cb.calls.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(cb.Close)
+	return cb
+}
+
+// newOutboxDB returns a migrated SQLite database in the default tenant.
+func newOutboxDB(t *testing.T) *gorm.DB {
+	db, err := database.Initialize(config.Database{Driver: "sqlite", Path: t.TempDir() + "/tmf632.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := database.NewMigrator(db, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.GormPlugin(false)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// sinkFunc adapts a function to events.Sink.
+type sinkFunc func(ctx context.Context, evt events.Event) error
+
+func (f sinkFunc) Deliver(ctx context.Context, evt events.Event) error {
+	return f(ctx, evt)
+}
+
+// hubSink returns a HubSink delivering once to each of callbacks, with
+// subscription ids s0, s1 and so on.
+func hubSink(callbacks ...*callback) *events.HubSink {
+	var subs subscriptions
+	for i, cb := range callbacks {
+		subs = append(subs, models.EventSubscription{ID: fmt.Sprint("s", i), Callback: cb.URL})
+	}
+	return &events.HubSink{Subscriptions: subs, Client: http.DefaultClient, Logger: zap.NewNop().Sugar(), MaxAttempts: 1}
+}
+
+// outboxTest is an outbox holding one published event.
+type outboxTest struct {
+	t      *testing.T
+	db     *gorm.DB
+	ctx    context.Context
+	outbox *events.Outbox
+}
+
+func newOutboxTest(t *testing.T) *outboxTest {
+	o := &outboxTest{t: t, db: newOutboxDB(t), ctx: tenant.NewContext(context.Background(), tenant.Default), outbox: events.NewOutbox()}
+	if err := o.outbox.Publish(o.db.WithContext(o.ctx), events.New(events.IndividualCreateEvent, "individual", map[string]string{"id": "i1"})); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// dispatcher returns a dispatcher for the outbox retrying without delay.
+func (o *outboxTest) dispatcher(opts events.DispatcherOptions, sinks ...events.Sink) *events.Dispatcher {
+	opts.RetryBackoff = time.Nanosecond
+	return events.NewDispatcher(o.db, o.outbox, zap.NewNop().Sugar(), opts, sinks...)
+}
+
+// dispatch runs one batch of d and returns the outbox row afterwards.
+func (o *outboxTest) dispatch(d *events.Dispatcher) models.OutboxEvent {
+	if _, err := d.DispatchBatch(o.ctx); err != nil {
+		o.t.Fatal(err)
+	}
+	return o.row()
+}
+
+func (o *outboxTest) row() models.OutboxEvent {
+	var row models.OutboxEvent
+	if err := o.db.WithContext(tenant.System(o.ctx)).First(&row).Error; err != nil {
+		o.t.Fatal(err)
+	}
+	return row
+}
+
+func TestDispatcherRetriesOnlyUnsettledSubscribers(t *testing.T) {
+	ok := newCallback(t, http.StatusNoContent)
+	flaky := newCallback(t, http.StatusServiceUnavailable, http.StatusNoContent)
+	gone := newCallback(t, http.StatusNotFound)
+	o := newOutboxTest(t)
+	d := o.dispatcher(events.DispatcherOptions{}, hubSink(ok, flaky, gone))
+
+	row := o.dispatch(d)
+	if row.Status != models.OutboxPending || row.Delivered != "s0 s2" {
+		t.Fatalf("first attempt: status %s, delivered %q", row.Status, row.Delivered)
+	}
+
+	row = o.dispatch(d)
+	if row.Status != models.OutboxSent || row.Delivered != "s0 s1 s2" {
+		t.Errorf("second attempt: status %s, delivered %q", row.Status, row.Delivered)
+	}
+	for name, cb := range map[string]*callback{"ok": ok, "flaky": flaky, "gone": gone} {
+		want := int32(1)
+		if cb == flaky {
+			want = 2
+		}
+		if got := cb.calls.Load(); got != want {
+			t.Errorf("%s was called %d times, want %d", name, got, want)
+		}
+	}
+}
+
+func TestDispatcherGivesUpOnPermanentFailures(t *testing.T) {
+	gone := newCallback(t, http.StatusNotFound)
+	forbidden := newCallback(t, http.StatusForbidden)
+	o := newOutboxTest(t)
+
+	row := o.dispatch(o.dispatcher(events.DispatcherOptions{}, hubSink(gone, forbidden)))
+	if row.Status != models.OutboxFailed || row.Attempts != 1 || row.Delivered != "s0 s1" {
+		t.Errorf("got status %s after %d attempts, delivered %q; want failed after 1", row.Status, row.Attempts, row.Delivered)
+	}
+}
+
+// blockingSink holds every delivery until release is closed, and reports
+// each one on started.
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{started: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (s *blockingSink) Deliver(ctx context.Context, evt events.Event) error {
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestDispatcherLease(t *testing.T) {
+	o := newOutboxTest(t)
+	var delivered atomic.Int32
+	count := sinkFunc(func(context.Context, events.Event) error {
+		delivered.Add(1)
+		return nil
+	})
+
+	// While one dispatcher delivers, its lease keeps the event from another
+	slow := newBlockingSink()
+	done := make(chan models.OutboxEvent)
+	go func() { done <- o.dispatch(o.dispatcher(events.DispatcherOptions{}, slow)) }()
+	<-slow.started
+	if n, err := o.dispatcher(events.DispatcherOptions{}, count).DispatchBatch(o.ctx); n != 0 || err != nil {
+		t.Errorf("leased event was claimed again: %d, %v", n, err)
+	}
+	close(slow.release)
+	if row := <-done; row.Status != models.OutboxSent || row.Attempts != 1 {
+		t.Errorf("got status %s after %d attempts, want sent after 1", row.Status, row.Attempts)
+	}
+	if delivered.Load() != 0 {
+		t.Errorf("event was delivered %d times by the second dispatcher", delivered.Load())
+	}
+}
+
+func TestDispatcherExpiredLease(t *testing.T) {
+	o := newOutboxTest(t)
+
+	// Once the lease runs out another dispatcher delivers the event, and
+	// the late outcome of the first is dropped
+	slow := newBlockingSink()
+	done := make(chan models.OutboxEvent)
+	go func() { done <- o.dispatch(o.dispatcher(events.DispatcherOptions{Lease: time.Nanosecond}, slow)) }()
+	<-slow.started
+	failing := sinkFunc(func(context.Context, events.Event) error { return errors.New("unavailable") })
+	row := o.dispatch(o.dispatcher(events.DispatcherOptions{}, failing))
+	if row.Status != models.OutboxPending || row.Attempts != 2 || row.LastError != "unavailable" {
+		t.Errorf("second claim: status %s after %d attempts, last error %q", row.Status, row.Attempts, row.LastError)
+	}
+	close(slow.release)
+	if row := <-done; row.Status != models.OutboxPending || row.LastError != "unavailable" {
+		t.Errorf("first claim overwrote the outcome: status %s, last error %q", row.Status, row.LastError)
+	}
+}
+
+func TestDispatcherReleasesOnShutdown(t *testing.T) {
+	o := newOutboxTest(t)
+	slow := newBlockingSink()
+	d := o.dispatcher(events.DispatcherOptions{}, slow)
+
+	ctx, cancel := context.WithCancel(o.ctx)
+	go func() {
+		<-slow.started
+		cancel()
+	}()
+	if _, err := d.DispatchBatch(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	row := o.row()
+	if row.Status != models.OutboxPending || row.Attempts != 0 || row.NextAttemptAt.After(time.Now()) {
+		t.Errorf("not released: status %s, %d attempts, next attempt at %s", row.Status, row.Attempts, row.NextAttemptAt)
+	}
+}
@@ -0,0 +1,97 @@
+// internal/events/relay.go
+package events
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Relay delivers events to its sinks from an in-memory queue. It stands in
+// for the outbox when parties are not stored in a database: delivery is
+// attempted once, and events still queued at shutdown are lost.
+type Relay struct {
+	queue  chan queuedEvent
+	sinks  []Sink
+	logger *zap.SugaredLogger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// queuedEvent is an event waiting for delivery with the traceparent of
+// the change that caused it.
+type queuedEvent struct {
+	evt         Event
+	traceParent string
+}
+
+func NewRelay(size int, logger *zap.SugaredLogger, sinks ...Sink) *Relay {
+	return &Relay{
+		queue:  make(chan queuedEvent, size),
+		sinks:  sinks,
+		logger: logger,
+	}
+}
+
+// Enqueue queues evt for delivery without blocking. When the queue is full
+// the event is dropped and logged. The delivery joins the trace in ctx.
+func (r *Relay) Enqueue(ctx context.Context, evt Event) {
+	select {
+	case r.queue <- queuedEvent{evt: evt, traceParent: traceParent(ctx)}:
+	default:
+		r.logger.Warnw("Dropping event, relay queue is full",
+			"eventId", evt.EventID,
+			"eventType", evt.EventType)
+	}
+}
+
+// Start launches the delivery loop.
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Stop cancels the delivery loop and waits for the current event to finish.
+func (r *Relay) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// Backlog counts the events queued for delivery.
+func (r *Relay) Backlog(context.Context) (int64, error) {
+	return int64(len(r.queue)), nil
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-r.queue:
+			r.deliver(ctx, queued)
+		}
+	}
+}
+
+func (r *Relay) deliver(ctx context.Context, queued queuedEvent) {
+	evt := queued.evt
+	ctx, span := startDelivery(withTraceParent(ctx, queued.traceParent), evt.EventID, evt.EventType)
+	defer span.End()
+
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, evt); err != nil {
+			endDelivery(span, err)
+			r.logger.Warnw("Event delivery failed",
+				"eventId", evt.EventID,
+				"eventType", evt.EventType,
+				"error", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// internal/events/trace.go
+package events
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/your-username/tmf632-service/internal/events")
+
+// traceParent returns the W3C traceparent of the span in ctx, or "" when
+// there is none.
+func traceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// withTraceParent returns ctx carrying the remote span described by
+// traceparent, so spans started from it join that trace.
+func withTraceParent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+}
+
+// startDelivery starts the span covering the delivery of one event to
+// every sink.
+func startDelivery(ctx context.Context, eventID, eventType string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "deliver "+eventType,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("event.id", eventID),
+			attribute.String("event.type", eventType),
+		),
+	)
+}
+
+// endDelivery records the outcome of a delivery on its span.
+func endDelivery(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
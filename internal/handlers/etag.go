@@ -0,0 +1,63 @@
+// internal/handlers/etag.go
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+)
+
+// etag is the strong entity tag for a resource version.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag sets the ETag response header for version.
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", etag(version))
+}
+
+// ifMatch reports whether the request's If-Match precondition holds for
+// version. A missing header always holds. Weak tags never match, as
+// If-Match uses the strong comparison.
+func ifMatch(c echo.Context, version int64) bool {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch guards a change to resource id, now at version: it fails
+// with 412 when If-Match names another version, and with 428 when the
+// header is missing but the configuration requires it.
+func (h *Handler) checkIfMatch(c echo.Context, resource, id string, version int64) error {
+	if h.Config.API.RequireIfMatch && c.Request().Header.Get("If-Match") == "" {
+		return apierror.PreconditionRequired(resource, id)
+	}
+	if !ifMatch(c, version) {
+		return apierror.PreconditionFailed(resource, id)
+	}
+	return nil
+}
+
+// ifNoneMatch reports whether the request's If-None-Match header names the
+// current version, using the weak comparison.
+func ifNoneMatch(c echo.Context, version int64) bool {
+	header := c.Request().Header.Get("If-None-Match")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// internal/handlers/etag_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/auth"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/patch"
+)
+
+func TestETags(t *testing.T) {
+	h, e := newTestHandler(t)
+	created := serve(e, h.CreateIndividual, request{method: "POST", body: `{"id":"a","givenName":"Ann"}`})
+	if created.Code != 201 || created.Header().Get("ETag") != `"1"` {
+		t.Fatalf("create: %d ETag %s", created.Code, created.Header().Get("ETag"))
+	}
+
+	get := func(tag string) int {
+		return serve(e, h.GetIndividual, request{method: "GET", id: "a", header: map[string]string{"If-None-Match": tag}}).Code
+	}
+	if code := get(`"1"`); code != 304 {
+		t.Errorf("If-None-Match current: got %d, want 304", code)
+	}
+	if code := get(`W/"1"`); code != 304 {
+		t.Errorf("If-None-Match weak: got %d, want 304", code)
+	}
+	if code := get(`"0"`); code != 200 {
+		t.Errorf("If-None-Match stale: got %d, want 200", code)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		requireTag  bool
+		ifMatch     string
+		contentType string
+		body        string
+		code        int
+		etag        string
+	}{
+		{"PUT without tag", "PUT", true, "", "", `{"givenName":"B"}`, 428, ""},
+		{"PATCH without tag", "PATCH", true, "", patch.MergePatchContentType, `{"givenName":"B"}`, 428, ""},
+		{"PUT stale tag", "PUT", true, `"7"`, "", `{"givenName":"B"}`, 412, ""},
+		{"PUT weak tag", "PUT", true, `W/"1"`, "", `{"givenName":"B"}`, 412, ""},
+		{"PUT current tag", "PUT", true, `"1"`, "", `{"givenName":"B"}`, 200, `"2"`},
+		{"PATCH one of the tags", "PATCH", true, `"1", "2"`, patch.MergePatchContentType, `{"givenName":"C"}`, 200, `"3"`},
+		{"PATCH any tag", "PATCH", true, `*`, patch.MergePatchContentType, `{"givenName":"D"}`, 200, `"4"`},
+		{"PATCH optional tag", "PATCH", false, "", patch.MergePatchContentType, `{"givenName":"E"}`, 200, `"5"`},
+		{"DELETE without tag", "DELETE", true, "", "", "", 428, ""},
+		{"DELETE stale tag", "DELETE", true, `"4"`, "", "", 412, ""},
+		{"DELETE current tag", "DELETE", true, `"5"`, "", "", 204, ""},
+		{"DELETE deleted party", "DELETE", true, `"5"`, "", "", 404, ""},
+	}
+	for _, tt := range tests {
+		h.Config.API.RequireIfMatch = tt.requireTag
+		handler := h.UpdateIndividual
+		switch tt.method {
+		case "PATCH":
+			handler = h.PatchIndividual
+		case "DELETE":
+			handler = h.DeleteIndividual
+		}
+		r := request{method: tt.method, id: "a", contentType: tt.contentType, body: tt.body}
+		if tt.ifMatch != "" {
+			r.header = map[string]string{"If-Match": tt.ifMatch}
+		}
+		rec := serve(e, handler, r)
+		if rec.Code != tt.code {
+			t.Errorf("%s: got %d, want %d: %s", tt.name, rec.Code, tt.code, rec.Body)
+		}
+		if got := rec.Header().Get("ETag"); tt.etag != "" && got != tt.etag {
+			t.Errorf("%s: got ETag %s, want %s", tt.name, got, tt.etag)
+		}
+	}
+}
+
+func TestUpdateRespondsWithStoredIndividual(t *testing.T) {
+	h, e := newTestHandler(t)
+	alice, bob := &auth.Principal{Subject: "alice"}, &auth.Principal{Subject: "bob"}
+	created := serve(e, h.CreateIndividual, request{method: "POST", body: `{"id":"a","givenName":"Ann"}`, principal: alice})
+	if created.Code != 201 {
+		t.Fatalf("create: %d %s", created.Code, created.Body)
+	}
+	var before models.Individual
+	if err := json.Unmarshal(created.Body.Bytes(), &before); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve(e, h.UpdateIndividual, request{
+		method:    "PUT",
+		id:        "a",
+		body:      `{"givenName":"Anne","contactMedium":[{"mediumType":"email","emailAddress":"anne@example.org"}]}`,
+		header:    map[string]string{"If-Match": `"1"`},
+		principal: bob,
+	})
+	if rec.Code != 200 || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("update: %d ETag %s: %s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	var after models.Individual
+	if err := json.Unmarshal(rec.Body.Bytes(), &after); err != nil {
+		t.Fatal(err)
+	}
+	if !after.CreationDate.Equal(before.CreationDate) || after.CreatedBy != "alice" || after.ModifiedBy != "bob" {
+		t.Errorf("server attributes: created %v by %q, modified by %q", after.CreationDate, after.CreatedBy, after.ModifiedBy)
+	}
+	if len(after.ContactMedium) != 1 || after.ContactMedium[0].ID == "" {
+		t.Errorf("contact medium without generated id: %+v", after.ContactMedium)
+	}
+}
@@ -2,540 +2,538 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
-	
+
 	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/auth"
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/ids"
+	"github.com/your-username/tmf632-service/internal/logger"
+	"github.com/your-username/tmf632-service/internal/masking"
 	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/repository"
+	"github.com/your-username/tmf632-service/internal/tenant"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
 type Handler struct {
-	DB     *gorm.DB
-	Logger *zap.SugaredLogger
-}
+	Parties       repository.PartyRepository
+	Subscriptions repository.SubscriptionRepository
+	Config        *config.Config
+	Logger        *zap.SugaredLogger
+	IDs           ids.Generator
+	// Masking hides or masks attributes from callers whose roles may not
+	// see them.
+	Masking masking.Policy
+	// Callbacks vets the callbacks of hub registrations.
+	Callbacks *events.CallbackGuard
+}
+
+func NewHandler(repo repository.Repository, cfg *config.Config, logger *zap.SugaredLogger) *Handler {
+	newID, err := ids.New(cfg.API.IDStrategy)
+	if err != nil {
+		// config.Load rejects unknown strategies, so this only guards
+		// hand-built configs
+		logger.Warnw("Falling back to UUID ids", "error", err)
+		newID, _ = ids.New(ids.StrategyUUID)
+	}
+	callbacks, err := events.NewCallbackGuard(cfg.Events.CallbackNetworks)
+	if err != nil {
+		logger.Warnw("Only admitting public callbacks", "error", err)
+		callbacks, _ = events.NewCallbackGuard(nil)
+	}
 
-func NewHandler(db *gorm.DB, logger *zap.SugaredLogger) *Handler {
 	return &Handler{
-		DB:     db,
-		Logger: logger,
+		Parties:       repo,
+		Subscriptions: repo,
+		Config:        cfg,
+		Logger:        logger,
+		IDs:           newID,
+		Callbacks:     callbacks,
 	}
 }
 
-type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+// log returns the logger for the request in c, annotated with its trace
+// and tenant.
+func (h *Handler) log(c echo.Context) *zap.SugaredLogger {
+	ctx := c.Request().Context()
+	log := logger.WithTrace(ctx, h.Logger)
+	if id, ok := tenant.FromContext(ctx); ok {
+		log = log.With("tenant", id)
+	}
+	return log
+}
+
+// author returns the subject of the authenticated caller, or "" when the
+// API is served without authentication and the client's createdBy and
+// modifiedBy are kept as sent.
+func author(c echo.Context) string {
+	if p, ok := auth.FromContext(c.Request().Context()); ok {
+		return p.Subject
+	}
+	return ""
+}
+
+// roles returns the roles of the authenticated caller.
+func roles(c echo.Context) []string {
+	if p, ok := auth.FromContext(c.Request().Context()); ok {
+		return p.Roles
+	}
+	return nil
+}
+
+// shape returns body, one resource or a list of them, with the attributes
+// the caller may not see hidden or masked.
+func (h *Handler) shape(c echo.Context, resource string, body interface{}) (interface{}, error) {
+	return h.Masking.Shape(resource, roles(c), body)
+}
+
+// restoreConcealed rewrites the body of a PUT replacing stored so that
+// the attributes the caller may not see in full keep their stored values,
+// rather than taking the masked form the caller read. A body showing such
+// an attribute other than it was read is rejected with 403.
+func (h *Handler) restoreConcealed(c echo.Context, resource string, stored interface{}) error {
+	if h.Masking.Visible(resource, roles(c), "") {
+		return nil
+	}
+	raw, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	restored, err := h.Masking.Restore(resource, roles(c), raw, stored)
+	var concealed *masking.ConcealedError
+	if errors.As(err, &concealed) {
+		return apierror.Forbidden(fmt.Sprintf("Changing %s is not permitted", concealed.Path))
+	}
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(restored))
+	return nil
+}
+
+// checkFilters rejects filters on attributes the caller may not see.
+func (h *Handler) checkFilters(c echo.Context, resource string, filters []query.Filter) error {
+	for _, f := range filters {
+		if !h.Masking.Visible(resource, roles(c), f.Path) {
+			return apierror.Forbidden(fmt.Sprintf("Filtering on %s is not permitted", f.Path))
+		}
+	}
+	return nil
+}
+
+// checkSort rejects sorting on attributes the caller may not see, whose
+// order would disclose them.
+func (h *Handler) checkSort(c echo.Context, resource string, sort query.Sort) error {
+	for _, key := range sort {
+		if !h.Masking.Visible(resource, roles(c), key.Path) {
+			return apierror.Forbidden(fmt.Sprintf("Sorting on %s is not permitted", key.Path))
+		}
+	}
+	return nil
+}
+
+// requestURL reconstructs the absolute URL the client called, for use in
+// pagination links.
+func requestURL(c echo.Context) *url.URL {
+	req := c.Request()
+	return &url.URL{
+		Scheme:   c.Scheme(),
+		Host:     req.Host,
+		Path:     req.URL.Path,
+		RawQuery: req.URL.RawQuery,
+	}
+}
+
+// createIndividualScope namespaces Idempotency-Key values sent to
+// CreateIndividual.
+const createIndividualScope = "POST /individual"
+
+// idempotencyScope namespaces Idempotency-Key values by operation and
+// caller, so no caller can replay the response another one got.
+func idempotencyScope(c echo.Context, operation string) string {
+	if subject := author(c); subject != "" {
+		return operation + " " + subject
+	}
+	return operation
 }
 
 func (h *Handler) CreateIndividual(c echo.Context) error {
 	start := time.Now()
-	h.Logger.Info("Starting CreateIndividual request")
-	
+	h.log(c).Info("Starting CreateIndividual request")
+
+	// A retried request carries the same Idempotency-Key; its payload is
+	// fingerprinted before Bind consumes the body
+	key := c.Request().Header.Get(idempotency.HeaderKey)
+	var requestHash string
+	if key != "" {
+		raw, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(raw))
+		requestHash = idempotency.Hash(raw)
+	}
+
 	var individual models.Individual
 	if err := c.Bind(&individual); err != nil {
-		h.Logger.Errorw("Failed to bind request body",
+		h.log(c).Errorw("Failed to bind request body",
 			"error", err,
 			"duration", time.Since(start))
-		return c.JSON(http.StatusBadRequest, Response{
-			Code:    http.StatusBadRequest,
-			Message: "Invalid request body",
-		})
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	if err := c.Validate(&individual); err != nil {
+		h.log(c).Errorw("Request body failed validation",
+			"error", err,
+			"duration", time.Since(start))
+		return err
 	}
 
+	if err := h.assignIDs(&individual, nil); err != nil {
+		h.log(c).Errorw("Client supplied ids outside import mode",
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+	individual.HREF = h.resourceURL(c, "individual", individual.ID)
 	individual.CreationDate = time.Now()
 	individual.ModificationDate = time.Now()
-	
-	if err := h.DB.Create(&individual).Error; err != nil {
-		h.Logger.Errorw("Failed to create individual",
+	individual.Version = 1
+	if subject := author(c); subject != "" {
+		individual.CreatedBy, individual.ModifiedBy = subject, subject
+	}
+
+	ctx := c.Request().Context()
+	scope := idempotencyScope(c, createIndividualScope)
+	var replay *idempotency.Response
+	if err := h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		if key != "" {
+			stored, err := tx.ClaimIdempotencyKey(ctx, scope, key, requestHash, h.Config.API.IdempotencyTTL)
+			if err != nil || stored != nil {
+				replay = stored
+				return err
+			}
+		}
+
+		if err := tx.CreateIndividual(ctx, &individual); err != nil {
+			return err
+		}
+		if err := h.audit(c, tx, "individual", individual.ID, models.AuditCreate, nil, individual); err != nil {
+			return err
+		}
+		if err := tx.Publish(ctx, events.New(events.IndividualCreateEvent, "individual", individual)); err != nil {
+			return err
+		}
+
+		if key == "" {
+			return nil
+		}
+		body, err := json.Marshal(individual)
+		if err != nil {
+			return err
+		}
+		return tx.CompleteIdempotencyKey(ctx, scope, key, idempotency.Response{
+			StatusCode: http.StatusCreated,
+			Headers: map[string]string{
+				echo.HeaderLocation: individual.HREF,
+				"ETag":              etag(individual.Version),
+			},
+			Body: body,
+		})
+	}); err != nil {
+		h.log(c).Errorw("Failed to create individual",
 			"error", err,
 			"duration", time.Since(start))
-			return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to create individual",
-        })
-    }
-
-    h.Logger.Infow("Successfully created individual",
-        "id", individual.ID,
-        "duration", time.Since(start))
-    
-    return c.JSON(http.StatusCreated, individual)
-}
-
-func (h *Handler) GetIndividual(c echo.Context) error {
-    start := time.Now()
-    id := c.Param("id")
-    h.Logger.Infow("Starting GetIndividual request", "id", id)
-
-    var individual models.Individual
-    if err := h.DB.Preload("ContactMedium").
-        Preload("ExternalReference").
-        Preload("IndividualIdentification").
-        Preload("PartyCharacteristic").
-        First(&individual, "id = ?", id).Error; err != nil {
-        
-        h.Logger.Errorw("Failed to get individual",
-            "id", id,
-            "error", err,
-            "duration", time.Since(start))
-            
-        if err == gorm.ErrRecordNotFound {
-            return c.JSON(http.StatusNotFound, Response{
-                Code:    http.StatusNotFound,
-                Message: "Individual not found",
-            })
-        }
-        
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to get individual",
-        })
-    }
-
-    h.Logger.Infow("Successfully retrieved individual",
-        "id", id,
-        "duration", time.Since(start))
-        
-    return c.JSON(http.StatusOK, individual)
-}
+		return apierror.Database("Failed to create individual", err)
+	}
 
-func (h *Handler) UpdateIndividual(c echo.Context) error {
-    start := time.Now()
-    id := c.Param("id")
-    h.Logger.Infow("Starting UpdateIndividual request", "id", id)
-
-    // First check if individual exists
-    var existingIndividual models.Individual
-    if err := h.DB.First(&existingIndividual, "id = ?", id).Error; err != nil {
-        if err == gorm.ErrRecordNotFound {
-            return c.JSON(http.StatusNotFound, Response{
-                Code:    http.StatusNotFound,
-                Message: "Individual not found",
-            })
-        }
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to check individual existence",
-        })
-    }
-
-    var updateIndividual models.Individual
-    if err := c.Bind(&updateIndividual); err != nil {
-        h.Logger.Errorw("Failed to bind request body",
-            "error", err,
-            "duration", time.Since(start))
-        return c.JSON(http.StatusBadRequest, Response{
-            Code:    http.StatusBadRequest,
-            Message: "Invalid request body",
-        })
-    }
-
-    updateIndividual.ID = id
-    updateIndividual.ModificationDate = time.Now()
-    
-    // Start a transaction
-    tx := h.DB.Begin()
-
-    // Update main individual record
-    if err := tx.Model(&existingIndividual).Updates(updateIndividual).Error; err != nil {
-        tx.Rollback()
-        h.Logger.Errorw("Failed to update individual",
-            "id", id,
-            "error", err,
-            "duration", time.Since(start))
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to update individual",
-        })
-    }
-
-    // Update related records if provided
-    if len(updateIndividual.ContactMedium) > 0 {
-        if err := tx.Model(&existingIndividual).Association("ContactMedium").Replace(updateIndividual.ContactMedium); err != nil {
-            tx.Rollback()
-            return c.JSON(http.StatusInternalServerError, Response{
-                Code:    http.StatusInternalServerError,
-                Message: "Failed to update contact medium",
-            })
-        }
-    }
-
-    if len(updateIndividual.ExternalReference) > 0 {
-        if err := tx.Model(&existingIndividual).Association("ExternalReference").Replace(updateIndividual.ExternalReference); err != nil {
-            tx.Rollback()
-            return c.JSON(http.StatusInternalServerError, Response{
-                Code:    http.StatusInternalServerError,
-                Message: "Failed to update external references",
-            })
-        }
-    }
-
-    if len(updateIndividual.IndividualIdentification) > 0 {
-        if err := tx.Model(&existingIndividual).Association("IndividualIdentification").Replace(updateIndividual.IndividualIdentification); err != nil {
-            tx.Rollback()
-            return c.JSON(http.StatusInternalServerError, Response{
-                Code:    http.StatusInternalServerError,
-                Message: "Failed to update individual identification",
-            })
-        }
-    }
-
-    if len(updateIndividual.PartyCharacteristic) > 0 {
-        if err := tx.Model(&existingIndividual).Association("PartyCharacteristic").Replace(updateIndividual.PartyCharacteristic); err != nil {
-            tx.Rollback()
-            return c.JSON(http.StatusInternalServerError, Response{
-                Code:    http.StatusInternalServerError,
-                Message: "Failed to update party characteristics",
-            })
-        }
-    }
-
-    // Commit transaction
-    if err := tx.Commit().Error; err != nil {
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to commit updates",
-        })
-    }
-
-    h.Logger.Infow("Successfully updated individual",
-        "id", id,
-        "duration", time.Since(start))
-        
-    return c.JSON(http.StatusOK, updateIndividual)
-}
+	if replay != nil {
+		h.log(c).Infow("Replayed idempotent create individual",
+			"idempotencyKey", key,
+			"duration", time.Since(start))
 
-func (h *Handler) DeleteIndividual(c echo.Context) error {
-    start := time.Now()
-    id := c.Param("id")
-    h.Logger.Infow("Starting DeleteIndividual request", "id", id)
-
-    tx := h.DB.Begin()
-
-    // Delete associated records first
-    if err := tx.Where("individual_id = ?", id).Delete(&models.ContactMedium{}).Error; err != nil {
-        tx.Rollback()
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to delete contact medium",
-        })
-    }
-
-    if err := tx.Where("individual_id = ?", id).Delete(&models.ExternalReference{}).Error; err != nil {
-        tx.Rollback()
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to delete external references",
-        })
-    }
-
-    if err := tx.Where("individual_id = ?", id).Delete(&models.IndividualIdentification{}).Error; err != nil {
-        tx.Rollback()
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to delete individual identification",
-        })
-    }
-
-    if err := tx.Where("individual_id = ?", id).Delete(&models.PartyCharacteristic{}).Error; err != nil {
-        tx.Rollback()
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to delete party characteristics",
-        })
-    }
-
-    // Delete the individual record
-    if err := tx.Delete(&models.Individual{}, "id = ?", id).Error; err != nil {
-        tx.Rollback()
-        h.Logger.Errorw("Failed to delete individual",
-            "id", id,
-            "error", err,
-            "duration", time.Since(start))
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to delete individual",
-        })
-    }
-
-    if err := tx.Commit().Error; err != nil {
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to commit deletion",
-        })
-    }
-
-    h.Logger.Infow("Successfully deleted individual",
-        "id", id,
-        "duration", time.Since(start))
-        
-    return c.JSON(http.StatusNoContent, nil)
-}
+		// The stored body is the individual as created; shape it for the
+		// caller like any other response
+		body, err := h.shape(c, "individual", json.RawMessage(replay.Body))
+		if err != nil {
+			return apierror.Internal("Failed to create individual", err)
+		}
+		for name, value := range replay.Headers {
+			c.Response().Header().Set(name, value)
+		}
+		return c.JSON(replay.StatusCode, body)
+	}
 
-func (h *Handler) ListIndividuals(c echo.Context) error {
-    start := time.Now()
-    h.Logger.Info("Starting ListIndividuals request")
-
-    var individuals []models.Individual
-    if err := h.DB.Find(&individuals).Error; err != nil {
-        h.Logger.Errorw("Failed to list individuals",
-            "error", err,
-            "duration", time.Since(start))
-        return c.JSON(http.StatusInternalServerError, Response{
-            Code:    http.StatusInternalServerError,
-            Message: "Failed to list individuals",
-        })
-    }
-
-    h.Logger.Infow("Successfully listed individuals",
-        "count", len(individuals),
-        "duration", time.Since(start))
-        
-    return c.JSON(http.StatusOK, individuals)
-}
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to create individual",
-})
-}
+	h.log(c).Infow("Successfully created individual",
+		"id", individual.ID,
+		"duration", time.Since(start))
 
-h.Logger.Infow("Successfully created individual",
-"id", individual.ID,
-"duration", time.Since(start))
+	body, err := h.shape(c, "individual", individual)
+	if err != nil {
+		return apierror.Internal("Failed to create individual", err)
+	}
 
-return c.JSON(http.StatusCreated, individual)
+	c.Response().Header().Set(echo.HeaderLocation, individual.HREF)
+	setETag(c, individual.Version)
+	return c.JSON(http.StatusCreated, body)
 }
 
 func (h *Handler) GetIndividual(c echo.Context) error {
-start := time.Now()
-id := c.Param("id")
-h.Logger.Infow("Starting GetIndividual request", "id", id)
-
-var individual models.Individual
-if err := h.DB.Preload("ContactMedium").
-Preload("ExternalReference").
-Preload("IndividualIdentification").
-Preload("PartyCharacteristic").
-First(&individual, "id = ?", id).Error; err != nil {
-
-h.Logger.Errorw("Failed to get individual",
-	"id", id,
-	"error", err,
-	"duration", time.Since(start))
-	
-if err == gorm.ErrRecordNotFound {
-	return c.JSON(http.StatusNotFound, Response{
-		Code:    http.StatusNotFound,
-		Message: "Individual not found",
-	})
-}
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting GetIndividual request", "id", id)
+
+	fields := query.ParseFields(c.QueryParams())
+	individual, err := h.Parties.GetIndividual(c.Request().Context(), id, fields)
+	if err != nil {
+		h.log(c).Errorw("Failed to get individual",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
 
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to get individual",
-})
-}
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Individual", id)
+		}
 
-h.Logger.Infow("Successfully retrieved individual",
-"id", id,
-"duration", time.Since(start))
+		return apierror.Database("Failed to get individual", err)
+	}
 
-return c.JSON(http.StatusOK, individual)
-}
+	// The ETag describes the full representation, so it is not sent for
+	// a fields selection
+	if fields.All() {
+		setETag(c, individual.Version)
+		if ifNoneMatch(c, individual.Version) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
 
-func (h *Handler) UpdateIndividual(c echo.Context) error {
-start := time.Now()
-id := c.Param("id")
-h.Logger.Infow("Starting UpdateIndividual request", "id", id)
-
-// First check if individual exists
-var existingIndividual models.Individual
-if err := h.DB.First(&existingIndividual, "id = ?", id).Error; err != nil {
-if err == gorm.ErrRecordNotFound {
-	return c.JSON(http.StatusNotFound, Response{
-		Code:    http.StatusNotFound,
-		Message: "Individual not found",
-	})
-}
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to check individual existence",
-})
-}
+	body, err := fields.Project(individual)
+	if err == nil {
+		body, err = h.shape(c, "individual", body)
+	}
+	if err != nil {
+		return apierror.Internal("Failed to get individual", err)
+	}
 
-var updateIndividual models.Individual
-if err := c.Bind(&updateIndividual); err != nil {
-h.Logger.Errorw("Failed to bind request body",
-	"error", err,
-	"duration", time.Since(start))
-return c.JSON(http.StatusBadRequest, Response{
-	Code:    http.StatusBadRequest,
-	Message: "Invalid request body",
-})
-}
+	h.log(c).Infow("Successfully retrieved individual",
+		"id", id,
+		"duration", time.Since(start))
 
-updateIndividual.ID = id
-updateIndividual.ModificationDate = time.Now()
-
-// Start a transaction
-tx := h.DB.Begin()
-
-// Update main individual record
-if err := tx.Model(&existingIndividual).Updates(updateIndividual).Error; err != nil {
-tx.Rollback()
-h.Logger.Errorw("Failed to update individual",
-	"id", id,
-	"error", err,
-	"duration", time.Since(start))
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to update individual",
-})
+	return c.JSON(http.StatusOK, body)
 }
 
-// Update related records if provided
-if len(updateIndividual.ContactMedium) > 0 {
-if err := tx.Model(&existingIndividual).Association("ContactMedium").Replace(updateIndividual.ContactMedium); err != nil {
-	tx.Rollback()
-	return c.JSON(http.StatusInternalServerError, Response{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to update contact medium",
-	})
-}
-}
+func (h *Handler) UpdateIndividual(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting UpdateIndividual request", "id", id)
+	ctx := c.Request().Context()
+
+	// First check if individual exists
+	existingIndividual, err := h.Parties.GetIndividual(ctx, id, query.Fields{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Individual", id)
+		}
+		return apierror.Database("Failed to check individual existence", err)
+	}
+	if err := h.checkIfMatch(c, "Individual", id, existingIndividual.Version); err != nil {
+		return err
+	}
+	if err := h.restoreConcealed(c, "individual", existingIndividual); err != nil {
+		h.log(c).Errorw("Update would change concealed attributes",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
 
-if len(updateIndividual.ExternalReference) > 0 {
-if err := tx.Model(&existingIndividual).Association("ExternalReference").Replace(updateIndividual.ExternalReference); err != nil {
-	tx.Rollback()
-	return c.JSON(http.StatusInternalServerError, Response{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to update external references",
-	})
-}
-}
+	var updateIndividual models.Individual
+	if err := c.Bind(&updateIndividual); err != nil {
+		h.log(c).Errorw("Failed to bind request body",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	if err := c.Validate(&updateIndividual); err != nil {
+		h.log(c).Errorw("Request body failed validation",
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
 
-if len(updateIndividual.IndividualIdentification) > 0 {
-if err := tx.Model(&existingIndividual).Association("IndividualIdentification").Replace(updateIndividual.IndividualIdentification); err != nil {
-	tx.Rollback()
-	return c.JSON(http.StatusInternalServerError, Response{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to update individual identification",
-	})
-}
-}
+	updateIndividual.ID = id
+	updateIndividual.HREF = h.resourceURL(c, "individual", id)
+	updateIndividual.ModificationDate = time.Now()
+	updateIndividual.Version = existingIndividual.Version + 1
+	if subject := author(c); subject != "" {
+		updateIndividual.CreatedBy, updateIndividual.ModifiedBy = existingIndividual.CreatedBy, subject
+	}
+
+	// New sub-resources in the body get server-generated ids; the others
+	// must be ones the party already has
+	if err := h.assignIDs(&updateIndividual, &existingIndividual); err != nil {
+		h.log(c).Errorw("Client supplied unknown sub-resource ids",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
 
-if len(updateIndividual.PartyCharacteristic) > 0 {
-if err := tx.Model(&existingIndividual).Association("PartyCharacteristic").Replace(updateIndividual.PartyCharacteristic); err != nil {
-	tx.Rollback()
-	return c.JSON(http.StatusInternalServerError, Response{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to update party characteristics",
+	var updated models.Individual
+	err = h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		// Update the individual, provided nobody changed it since it was
+		// read
+		if err := tx.UpdateIndividual(ctx, &updateIndividual, existingIndividual.Version); err != nil {
+			return err
+		}
+
+		// Record change events in the same transaction
+		var err error
+		if updated, err = tx.GetIndividual(ctx, id, query.Fields{}); err != nil {
+			return err
+		}
+		if err := h.audit(c, tx, "individual", id, models.AuditUpdate, existingIndividual, updated); err != nil {
+			return err
+		}
+		if err := tx.Publish(ctx, events.New(events.IndividualAttributeValueChangeEvent, "individual", updated)); err != nil {
+			return err
+		}
+		if updated.Status != existingIndividual.Status {
+			return tx.Publish(ctx, events.New(events.IndividualStateChangeEvent, "individual", updated))
+		}
+		return nil
 	})
-}
-}
+	if err != nil {
+		h.log(c).Errorw("Failed to update individual",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
 
-// Commit transaction
-if err := tx.Commit().Error; err != nil {
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to commit updates",
-})
-}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return apierror.PreconditionFailed("Individual", id)
+		}
+		return apierror.Database("Failed to update individual", err)
+	}
 
-h.Logger.Infow("Successfully updated individual",
-"id", id,
-"duration", time.Since(start))
+	h.log(c).Infow("Successfully updated individual",
+		"id", id,
+		"duration", time.Since(start))
 
-return c.JSON(http.StatusOK, updateIndividual)
+	// Respond with what was stored, including the attributes the server
+	// keeps, rather than with the request body
+	setETag(c, updated.Version)
+	body, err := h.shape(c, "individual", updated)
+	if err != nil {
+		return apierror.Internal("Failed to update individual", err)
+	}
+	return c.JSON(http.StatusOK, body)
 }
 
 func (h *Handler) DeleteIndividual(c echo.Context) error {
-start := time.Now()
-id := c.Param("id")
-h.Logger.Infow("Starting DeleteIndividual request", "id", id)
-
-tx := h.DB.Begin()
-
-// Delete associated records first
-if err := tx.Where("individual_id = ?", id).Delete(&models.ContactMedium{}).Error; err != nil {
-tx.Rollback()
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to delete contact medium",
-})
-}
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting DeleteIndividual request", "id", id)
+	ctx := c.Request().Context()
+
+	err := h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		// Keep the last state for the delete event
+		deleted, err := tx.GetIndividual(ctx, id, query.Fields{})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Individual", id)
+		}
+		if err != nil {
+			return err
+		}
+		if err := h.checkIfMatch(c, "Individual", id, deleted.Version); err != nil {
+			return err
+		}
+
+		// Delete the individual, provided it is still the version the
+		// delete event was built from
+		if err := tx.DeleteIndividual(ctx, id, deleted.Version); err != nil {
+			return err
+		}
+		if err := h.audit(c, tx, "individual", id, models.AuditDelete, deleted, nil); err != nil {
+			return err
+		}
+		return tx.Publish(ctx, events.New(events.IndividualDeleteEvent, "individual", deleted))
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to delete individual",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
 
-if err := tx.Where("individual_id = ?", id).Delete(&models.ExternalReference{}).Error; err != nil {
-tx.Rollback()
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to delete external references",
-})
-}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return apierror.PreconditionFailed("Individual", id)
+		}
+		return apierror.Database("Failed to delete individual", err)
+	}
 
-if err := tx.Where("individual_id = ?", id).Delete(&models.IndividualIdentification{}).Error; err != nil {
-tx.Rollback()
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to delete individual identification",
-})
-}
+	h.log(c).Infow("Successfully deleted individual",
+		"id", id,
+		"duration", time.Since(start))
 
-if err := tx.Where("individual_id = ?", id).Delete(&models.PartyCharacteristic{}).Error; err != nil {
-tx.Rollback()
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to delete party characteristics",
-})
+	return c.JSON(http.StatusNoContent, nil)
 }
 
-// Delete the individual record
-if err := tx.Delete(&models.Individual{}, "id = ?", id).Error; err != nil {
-tx.Rollback()
-h.Logger.Errorw("Failed to delete individual",
-	"id", id,
-	"error", err,
-	"duration", time.Since(start))
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to delete individual",
-})
-}
+func (h *Handler) ListIndividuals(c echo.Context) error {
+	start := time.Now()
+	h.log(c).Info("Starting ListIndividuals request")
 
-if err := tx.Commit().Error; err != nil {
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to commit deletion",
-})
-}
+	page, err := query.ParsePage(c.QueryParams(), h.Config.API.DefaultPageSize, h.Config.API.MaxPageSize)
+	if err != nil {
+		return err
+	}
 
-h.Logger.Infow("Successfully deleted individual",
-"id", id,
-"duration", time.Since(start))
+	filters := query.ParseFilters(c.QueryParams())
+	if err := h.checkFilters(c, "individual", filters); err != nil {
+		return err
+	}
+	sort := query.ParseSort(c.QueryParams())
+	if err := h.checkSort(c, "individual", sort); err != nil {
+		return err
+	}
 
-return c.JSON(http.StatusNoContent, nil)
-}
+	fields := query.ParseFields(c.QueryParams())
+	individuals, total, err := h.Parties.ListIndividuals(c.Request().Context(), repository.ListOptions{
+		Filters: filters,
+		Sort:    sort,
+		Page:    page,
+		Fields:  fields,
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to list individuals",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to list individuals", err)
+	}
 
-func (h *Handler) ListIndividuals(c echo.Context) error {
-start := time.Now()
-h.Logger.Info("Starting ListIndividuals request")
-
-var individuals []models.Individual
-if err := h.DB.Find(&individuals).Error; err != nil {
-h.Logger.Errorw("Failed to list individuals",
-	"error", err,
-	"duration", time.Since(start))
-return c.JSON(http.StatusInternalServerError, Response{
-	Code:    http.StatusInternalServerError,
-	Message: "Failed to list individuals",
-})
-}
+	body, err := fields.Project(individuals)
+	if err == nil {
+		body, err = h.shape(c, "individual", body)
+	}
+	if err != nil {
+		return apierror.Internal("Failed to list individuals", err)
+	}
+
+	status := page.WriteHeaders(c.Response().Header(), requestURL(c), total, len(individuals))
 
-h.Logger.Infow("Successfully listed individuals",
-"count", len(individuals),
-"duration", time.Since(start))
+	h.log(c).Infow("Successfully listed individuals",
+		"count", len(individuals),
+		"total", total,
+		"duration", time.Since(start))
 
-return c.JSON(http.StatusOK, individuals)
+	return c.JSON(status, body)
 }
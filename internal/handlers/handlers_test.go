@@ -0,0 +1,159 @@
+// internal/handlers/handlers_test.go
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/auth"
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/masking"
+	"github.com/your-username/tmf632-service/internal/repository"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"github.com/your-username/tmf632-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// request is a call to a handler in a test.
+type request struct {
+	method      string
+	query       string
+	contentType string
+	body        string
+	id          string
+	header      map[string]string
+	principal   *auth.Principal
+}
+
+func newTestHandler(t *testing.T) (*Handler, *echo.Echo) {
+	t.Helper()
+	cfg := &config.Config{API: config.API{
+		DefaultPageSize: 10,
+		MaxPageSize:     20,
+		ImportMode:      true,
+		IdempotencyTTL:  time.Hour,
+		BasePath:        "/tmf-api/partyManagement/v4",
+	}}
+	e := echo.New()
+	e.HTTPErrorHandler = apierror.NewHTTPErrorHandler(zap.NewNop().Sugar())
+	e.Validator = validation.NewValidator()
+	return NewHandler(repository.NewMemory(nil), cfg, zap.NewNop().Sugar()), e
+}
+
+// serve runs fn for r in the default tenant and returns the response.
+func serve(e *echo.Echo, fn echo.HandlerFunc, r request) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(r.method, "/individual?"+r.query, bytes.NewBufferString(r.body))
+	contentType := r.contentType
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+	req.Header.Set(echo.HeaderContentType, contentType)
+	for name, value := range r.header {
+		req.Header.Set(name, value)
+	}
+	ctx := tenant.NewContext(req.Context(), tenant.Default)
+	if r.principal != nil {
+		ctx = auth.NewContext(ctx, r.principal)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req.WithContext(ctx), rec)
+	if r.id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(r.id)
+	}
+	if err := fn(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+	return rec
+}
+
+func TestUpdateKeepsConcealedAttributes(t *testing.T) {
+	h, e := newTestHandler(t)
+	h.Masking = masking.Policy{"individual": {
+		{Path: "individualIdentification.identificationId", Default: masking.Hide, Keep: 4, Roles: map[string]masking.Action{"call-centre": masking.Mask, "kyc": masking.Reveal}},
+		{Path: "contactMedium.street1", Default: masking.Hide, Roles: map[string]masking.Action{"kyc": masking.Reveal}},
+	}}
+	callCentre := &auth.Principal{Subject: "agent", Roles: []string{"call-centre"}}
+	kyc := &auth.Principal{Subject: "kyc", Roles: []string{"kyc"}}
+	created := serve(e, h.CreateIndividual, request{
+		method: "POST",
+		body: `{"id":"a","givenName":"Ann",
+			"individualIdentification":[{"id":"p","identificationType":"passport","identificationId":"P1234567"}],
+			"contactMedium":[{"id":"m","mediumType":"postalAddress","street1":"Main Street 1","city":"Utrecht"}]}`,
+	})
+	if created.Code != 201 {
+		t.Fatalf("create: %d %s", created.Code, created.Body)
+	}
+
+	// The call centre writes back what it read, changing only the city
+	read := serve(e, h.GetIndividual, request{method: "GET", id: "a", principal: callCentre})
+	if !strings.Contains(read.Body.String(), `"identificationId":"****4567"`) || strings.Contains(read.Body.String(), "Main Street") {
+		t.Fatalf("read: %s", read.Body)
+	}
+	body := strings.Replace(read.Body.String(), "Utrecht", "Amersfoort", 1)
+	put := func(body string) *httptest.ResponseRecorder {
+		version := serve(e, h.GetIndividual, request{method: "GET", id: "a"}).Header().Get("ETag")
+		return serve(e, h.UpdateIndividual, request{
+			method:    "PUT",
+			id:        "a",
+			body:      body,
+			header:    map[string]string{"If-Match": version},
+			principal: callCentre,
+		})
+	}
+	if rec := put(body); rec.Code != 200 {
+		t.Fatalf("round trip: %d %s", rec.Code, rec.Body)
+	}
+
+	stored := serve(e, h.GetIndividual, request{method: "GET", id: "a", principal: kyc}).Body.String()
+	for _, want := range []string{`"identificationId":"P1234567"`, `"street1":"Main Street 1"`, `"city":"Amersfoort"`} {
+		if !strings.Contains(stored, want) {
+			t.Errorf("stored party lacks %s: %s", want, stored)
+		}
+	}
+
+	for _, change := range []struct{ old, new string }{
+		{`"identificationId":"****4567"`, `"identificationId":"X0000000"`},
+		{`"mediumType":"postalAddress"`, `"mediumType":"postalAddress","street1":"Elsewhere 2"`},
+	} {
+		if rec := put(strings.Replace(body, change.old, change.new, 1)); rec.Code != 403 {
+			t.Errorf("%s: got %d, want 403", change.new, rec.Code)
+		}
+	}
+}
+
+func TestListSort(t *testing.T) {
+	h, e := newTestHandler(t)
+	h.Masking = masking.Policy{"individual": {{Path: "familyName", Default: masking.Hide}}}
+	for _, body := range []string{`{"id":"a","givenName":"Bob"}`, `{"id":"b","givenName":"Ann"}`} {
+		if rec := serve(e, h.CreateIndividual, request{method: "POST", body: body}); rec.Code != 201 {
+			t.Fatalf("create: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	tests := []struct {
+		query string
+		code  int
+		// want must appear in the body before then
+		want, then string
+	}{
+		{"sort=givenName", 200, `"id":"b"`, `"id":"a"`},
+		{"sort=-givenName", 200, `"id":"a"`, `"id":"b"`},
+		{"sort=bogus", 400, `"INVALID_QUERY"`, ""},
+		// The order would disclose a hidden attribute
+		{"sort=familyName", 403, `"FORBIDDEN"`, ""},
+	}
+	for _, tt := range tests {
+		rec := serve(e, h.ListIndividuals, request{method: "GET", query: tt.query})
+		body := rec.Body.String()
+		i := strings.Index(body, tt.want)
+		if rec.Code != tt.code || i < 0 || i > strings.LastIndex(body, tt.then) && tt.then != "" {
+			t.Errorf("%s: got %d %s", tt.query, rec.Code, body)
+		}
+	}
+}
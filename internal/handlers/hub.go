@@ -0,0 +1,79 @@
+// internal/handlers/hub.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/repository"
+)
+
+func (h *Handler) RegisterListener(c echo.Context) error {
+	start := time.Now()
+	h.log(c).Info("Starting RegisterListener request")
+
+	var subscription models.EventSubscription
+	if err := c.Bind(&subscription); err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+
+	if err := h.Callbacks.Check(c.Request().Context(), subscription.Callback); err != nil {
+		h.log(c).Errorw("Rejected listener callback",
+			"callback", subscription.Callback,
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.BadRequest(apierror.CodeValidationFailed, "callback must be an absolute http(s) URL of a public host").Wrap(err)
+	}
+	if _, err := url.ParseQuery(subscription.Query); err != nil {
+		return apierror.BadRequest(apierror.CodeValidationFailed, "query must be a URL query string")
+	}
+
+	subscription.ID = h.IDs()
+	subscription.Roles = strings.Join(roles(c), " ")
+	subscription.Owner = author(c)
+	subscription.CreationDate = time.Now()
+
+	if err := h.Subscriptions.CreateSubscription(c.Request().Context(), &subscription); err != nil {
+		h.log(c).Errorw("Failed to register listener",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to register listener", err)
+	}
+
+	h.log(c).Infow("Successfully registered listener",
+		"id", subscription.ID,
+		"callback", subscription.Callback,
+		"duration", time.Since(start))
+
+	return c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *Handler) UnregisterListener(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting UnregisterListener request", "id", id)
+
+	// Other callers' listeners are reported as not found
+	if err := h.Subscriptions.DeleteSubscription(c.Request().Context(), id, author(c)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Listener", id)
+		}
+		h.log(c).Errorw("Failed to unregister listener",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to unregister listener", err)
+	}
+
+	h.log(c).Infow("Successfully unregistered listener",
+		"id", id,
+		"duration", time.Since(start))
+
+	return c.NoContent(http.StatusNoContent)
+}
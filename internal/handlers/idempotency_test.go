@@ -0,0 +1,68 @@
+// internal/handlers/idempotency_test.go
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/auth"
+	"github.com/your-username/tmf632-service/internal/masking"
+)
+
+func TestIdempotentCreate(t *testing.T) {
+	h, e := newTestHandler(t)
+	h.Config.API.ImportMode = false
+	post := func(key, body string) *httptest.ResponseRecorder {
+		return serve(e, h.CreateIndividual, request{method: "POST", body: body, header: map[string]string{"Idempotency-Key": key}})
+	}
+
+	first := post("k1", `{"givenName":"Ann"}`)
+	if first.Code != 201 {
+		t.Fatalf("create: %d %s", first.Code, first.Body)
+	}
+	// The same payload, formatted differently, replays the first response
+	replay := post("k1", `{ "givenName" : "Ann" }`)
+	if replay.Code != 201 || replay.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("replay: %d %s, want 201 %s", replay.Code, replay.Header().Get("Location"), first.Header().Get("Location"))
+	}
+	if r := post("k1", `{"givenName":"Bob"}`); r.Code != 422 {
+		t.Errorf("reused key: got %d, want 422", r.Code)
+	}
+	if r := post("k2", `{"givenName":"Ann"}`); r.Code != 201 || r.Header().Get("Location") == first.Header().Get("Location") {
+		t.Errorf("new key: %d %s", r.Code, r.Header().Get("Location"))
+	}
+}
+
+func TestIdempotentReplayIsShaped(t *testing.T) {
+	h, e := newTestHandler(t)
+	h.Config.API.ImportMode = false
+	h.Masking = masking.Policy{"individual": {
+		{Path: "familyName", Default: masking.Hide, Roles: map[string]masking.Action{"admin": masking.Reveal}},
+	}}
+	post := func(p *auth.Principal) *httptest.ResponseRecorder {
+		return serve(e, h.CreateIndividual, request{
+			method:    "POST",
+			body:      `{"givenName":"Ann","familyName":"Secret"}`,
+			header:    map[string]string{"Idempotency-Key": "k1"},
+			principal: p,
+		})
+	}
+
+	alice := &auth.Principal{Subject: "alice"}
+	first, replay := post(alice), post(alice)
+	if first.Code != 201 || replay.Code != 201 || replay.Header().Get("Location") != first.Header().Get("Location") {
+		t.Fatalf("replay: %d %d", first.Code, replay.Code)
+	}
+	for _, r := range []*httptest.ResponseRecorder{first, replay} {
+		if strings.Contains(r.Body.String(), "Secret") {
+			t.Errorf("hidden attribute in response: %s", r.Body)
+		}
+	}
+
+	// Keys are scoped to the caller, so bob creates his own party
+	bob := post(&auth.Principal{Subject: "bob", Roles: []string{"admin"}})
+	if bob.Code != 201 || bob.Header().Get("Location") == first.Header().Get("Location") {
+		t.Errorf("key shared between callers: %d %s", bob.Code, bob.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,96 @@
+// internal/handlers/ids.go
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/validation"
+	"gorm.io/gorm/schema"
+)
+
+// assignIDs gives the party held by model, and every sub-resource it owns,
+// a server-generated id. existing is the stored party model replaces, or nil
+// for a new one. Outside import mode, the only ids the client may supply are
+// those existing already has; any other is reported as a violation.
+func (h *Handler) assignIDs(model, existing interface{}) error {
+	sch, err := query.ParseSchema(model)
+	if err != nil {
+		return err
+	}
+
+	var owned map[string]bool
+	if existing != nil {
+		owned = map[string]bool{}
+		ownedIDs(sch, reflect.Indirect(reflect.ValueOf(existing)), owned)
+	}
+
+	var violations validation.Errors
+	h.assignID(sch, reflect.Indirect(reflect.ValueOf(model)), "", owned, &violations)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+func (h *Handler) assignID(sch *schema.Schema, value reflect.Value, pointer string, owned map[string]bool, violations *validation.Errors) {
+	ctx := context.Background()
+	pk := sch.PrioritizedPrimaryField
+	if pk != nil && pk.FieldType.Kind() == reflect.String {
+		id, zero := pk.ValueOf(ctx, value)
+		switch {
+		case zero || id == "":
+			pk.Set(ctx, value, h.IDs())
+		case h.Config.API.ImportMode || owned[sch.Table+"/"+id.(string)]:
+		case owned != nil:
+			*violations = append(*violations, validation.Violation{
+				Pointer: pointer + "/id",
+				Rule:    "readonly",
+				Message: "is not the id of an entry of this party; new entries get server-generated ids",
+			})
+		default:
+			*violations = append(*violations, validation.Violation{
+				Pointer: pointer + "/id",
+				Rule:    "readonly",
+				Message: "is assigned by the server unless import mode is enabled",
+			})
+		}
+	}
+
+	for _, rel := range sch.Relationships.HasMany {
+		name, ok := query.JSONPath(sch.ModelType, []string{rel.Name})
+		if !ok {
+			continue
+		}
+		children := rel.Field.ReflectValueOf(ctx, value)
+		for i := 0; i < children.Len(); i++ {
+			h.assignID(rel.FieldSchema, children.Index(i), pointer+"/"+name+"/"+strconv.Itoa(i), owned, violations)
+		}
+	}
+}
+
+// ownedIDs adds the ids of the party in value and of its sub-resources to
+// owned, keyed by table so that equal ids of different kinds stay apart.
+func ownedIDs(sch *schema.Schema, value reflect.Value, owned map[string]bool) {
+	ctx := context.Background()
+	if pk := sch.PrioritizedPrimaryField; pk != nil && pk.FieldType.Kind() == reflect.String {
+		if id, zero := pk.ValueOf(ctx, value); !zero {
+			owned[sch.Table+"/"+id.(string)] = true
+		}
+	}
+	for _, rel := range sch.Relationships.HasMany {
+		children := rel.Field.ReflectValueOf(ctx, value)
+		for i := 0; i < children.Len(); i++ {
+			ownedIDs(rel.FieldSchema, children.Index(i), owned)
+		}
+	}
+}
+
+// resourceURL is the absolute href of the resource collection/id, built
+// from the base URL the client called and the configured API prefix.
+func (h *Handler) resourceURL(c echo.Context, collection, id string) string {
+	return c.Scheme() + "://" + c.Request().Host + h.Config.API.BasePath + "/" + collection + "/" + id
+}
@@ -0,0 +1,283 @@
+// internal/handlers/organization.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/repository"
+)
+
+func (h *Handler) CreateOrganization(c echo.Context) error {
+	start := time.Now()
+	h.log(c).Info("Starting CreateOrganization request")
+
+	var organization models.Organization
+	if err := c.Bind(&organization); err != nil {
+		h.log(c).Errorw("Failed to bind request body",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	if err := c.Validate(&organization); err != nil {
+		h.log(c).Errorw("Request body failed validation",
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+
+	if err := h.assignIDs(&organization, nil); err != nil {
+		h.log(c).Errorw("Client supplied ids outside import mode",
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+	organization.HREF = h.resourceURL(c, "organization", organization.ID)
+	organization.CreationDate = time.Now()
+	organization.ModificationDate = time.Now()
+	if subject := author(c); subject != "" {
+		organization.CreatedBy, organization.ModifiedBy = subject, subject
+	}
+
+	ctx := c.Request().Context()
+	if err := h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		if err := tx.CreateOrganization(ctx, &organization); err != nil {
+			return err
+		}
+		return h.audit(c, tx, "organization", organization.ID, models.AuditCreate, nil, organization)
+	}); err != nil {
+		h.log(c).Errorw("Failed to create organization",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to create organization", err)
+	}
+
+	h.log(c).Infow("Successfully created organization",
+		"id", organization.ID,
+		"duration", time.Since(start))
+
+	body, err := h.shape(c, "organization", organization)
+	if err != nil {
+		return apierror.Internal("Failed to create organization", err)
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, organization.HREF)
+	return c.JSON(http.StatusCreated, body)
+}
+
+func (h *Handler) GetOrganization(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting GetOrganization request", "id", id)
+
+	fields := query.ParseFields(c.QueryParams())
+	organization, err := h.Parties.GetOrganization(c.Request().Context(), id, fields)
+	if err != nil {
+		h.log(c).Errorw("Failed to get organization",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Organization", id)
+		}
+
+		return apierror.Database("Failed to get organization", err)
+	}
+
+	body, err := fields.Project(organization)
+	if err == nil {
+		body, err = h.shape(c, "organization", body)
+	}
+	if err != nil {
+		return apierror.Internal("Failed to get organization", err)
+	}
+
+	h.log(c).Infow("Successfully retrieved organization",
+		"id", id,
+		"duration", time.Since(start))
+
+	return c.JSON(http.StatusOK, body)
+}
+
+func (h *Handler) UpdateOrganization(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting UpdateOrganization request", "id", id)
+	ctx := c.Request().Context()
+
+	// First check if organization exists
+	existingOrganization, err := h.Parties.GetOrganization(ctx, id, query.Fields{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Organization", id)
+		}
+		return apierror.Database("Failed to check organization existence", err)
+	}
+	if err := h.restoreConcealed(c, "organization", existingOrganization); err != nil {
+		h.log(c).Errorw("Update would change concealed attributes",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+
+	var updateOrganization models.Organization
+	if err := c.Bind(&updateOrganization); err != nil {
+		h.log(c).Errorw("Failed to bind request body",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	if err := c.Validate(&updateOrganization); err != nil {
+		h.log(c).Errorw("Request body failed validation",
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+
+	updateOrganization.ID = id
+	updateOrganization.HREF = h.resourceURL(c, "organization", id)
+	updateOrganization.ModificationDate = time.Now()
+	if subject := author(c); subject != "" {
+		updateOrganization.CreatedBy, updateOrganization.ModifiedBy = existingOrganization.CreatedBy, subject
+	}
+
+	// New sub-resources in the body get server-generated ids; the others
+	// must be ones the party already has
+	if err := h.assignIDs(&updateOrganization, &existingOrganization); err != nil {
+		h.log(c).Errorw("Client supplied unknown sub-resource ids",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return err
+	}
+
+	var updated models.Organization
+	err = h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		// Organizations are not versioned, so the state the update replaces
+		// is read again in the transaction
+		before, err := tx.GetOrganization(ctx, id, query.Fields{})
+		if err != nil {
+			return err
+		}
+		if err := tx.UpdateOrganization(ctx, &updateOrganization); err != nil {
+			return err
+		}
+		if updated, err = tx.GetOrganization(ctx, id, query.Fields{}); err != nil {
+			return err
+		}
+		return h.audit(c, tx, "organization", id, models.AuditUpdate, before, updated)
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to update organization",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Organization", id)
+		}
+		return apierror.Database("Failed to update organization", err)
+	}
+
+	h.log(c).Infow("Successfully updated organization",
+		"id", id,
+		"duration", time.Since(start))
+
+	body, err := h.shape(c, "organization", updated)
+	if err != nil {
+		return apierror.Internal("Failed to update organization", err)
+	}
+	return c.JSON(http.StatusOK, body)
+}
+
+func (h *Handler) DeleteOrganization(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting DeleteOrganization request", "id", id)
+	ctx := c.Request().Context()
+
+	err := h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		// Keep the last state for the audit trail
+		deleted, err := tx.GetOrganization(ctx, id, query.Fields{})
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteOrganization(ctx, id); err != nil {
+			return err
+		}
+		return h.audit(c, tx, "organization", id, models.AuditDelete, deleted, nil)
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to delete organization",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to delete organization", err)
+	}
+
+	h.log(c).Infow("Successfully deleted organization",
+		"id", id,
+		"duration", time.Since(start))
+
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *Handler) ListOrganizations(c echo.Context) error {
+	start := time.Now()
+	h.log(c).Info("Starting ListOrganizations request")
+
+	page, err := query.ParsePage(c.QueryParams(), h.Config.API.DefaultPageSize, h.Config.API.MaxPageSize)
+	if err != nil {
+		return err
+	}
+
+	filters := query.ParseFilters(c.QueryParams())
+	if err := h.checkFilters(c, "organization", filters); err != nil {
+		return err
+	}
+	sort := query.ParseSort(c.QueryParams())
+	if err := h.checkSort(c, "organization", sort); err != nil {
+		return err
+	}
+
+	fields := query.ParseFields(c.QueryParams())
+	organizations, total, err := h.Parties.ListOrganizations(c.Request().Context(), repository.ListOptions{
+		Filters: filters,
+		Sort:    sort,
+		Page:    page,
+		Fields:  fields,
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to list organizations",
+			"error", err,
+			"duration", time.Since(start))
+		return apierror.Database("Failed to list organizations", err)
+	}
+
+	body, err := fields.Project(organizations)
+	if err == nil {
+		body, err = h.shape(c, "organization", body)
+	}
+	if err != nil {
+		return apierror.Internal("Failed to list organizations", err)
+	}
+
+	status := page.WriteHeaders(c.Response().Header(), requestURL(c), total, len(organizations))
+
+	h.log(c).Infow("Successfully listed organizations",
+		"count", len(organizations),
+		"total", total,
+		"duration", time.Since(start))
+
+	return c.JSON(status, body)
+}
@@ -0,0 +1,137 @@
+// internal/handlers/patch.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/apierror"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/patch"
+	"github.com/your-username/tmf632-service/internal/repository"
+)
+
+// individualReadOnly are the Individual attributes a PATCH may not change,
+// including the bookkeeping fields gorm.Model exposes in JSON.
+var individualReadOnly = []string{
+	"id", "href", "creationDate", "createdBy",
+	"ID", "CreatedAt", "UpdatedAt", "DeletedAt",
+}
+
+func (h *Handler) PatchIndividual(c echo.Context) error {
+	start := time.Now()
+	id := c.Param("id")
+	h.log(c).Infow("Starting PatchIndividual request", "id", id)
+	ctx := c.Request().Context()
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return apierror.BadRequest(apierror.CodeInvalidBody, "Invalid request body").Wrap(err)
+	}
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+
+	var patched models.Individual
+	err = h.Parties.Transaction(ctx, func(tx repository.PartyRepository) error {
+		var existing models.Individual
+		var err error
+		patched, err = tx.PatchIndividual(ctx, id, func(current models.Individual) (models.Individual, error) {
+			existing = current
+			return h.applyIndividualPatch(c, current, contentType, body)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := h.audit(c, tx, "individual", id, models.AuditPatch, existing, patched); err != nil {
+			return err
+		}
+		if err := tx.Publish(ctx, events.New(events.IndividualAttributeValueChangeEvent, "individual", patched)); err != nil {
+			return err
+		}
+		if patched.Status != existing.Status {
+			return tx.Publish(ctx, events.New(events.IndividualStateChangeEvent, "individual", patched))
+		}
+		return nil
+	})
+	if err != nil {
+		h.log(c).Errorw("Failed to patch individual",
+			"id", id,
+			"error", err,
+			"duration", time.Since(start))
+
+		if errors.Is(err, repository.ErrNotFound) {
+			return apierror.NotFound("Individual", id)
+		}
+		return apierror.Database("Failed to patch individual", err)
+	}
+
+	h.log(c).Infow("Successfully patched individual",
+		"id", id,
+		"duration", time.Since(start))
+
+	shaped, err := h.shape(c, "individual", patched)
+	if err != nil {
+		return apierror.Internal("Failed to patch individual", err)
+	}
+
+	setETag(c, patched.Version)
+	return c.JSON(http.StatusOK, shaped)
+}
+
+// applyIndividualPatch applies the patch document body to existing and
+// returns the validated result, at the next version.
+func (h *Handler) applyIndividualPatch(c echo.Context, existing models.Individual, contentType string, body []byte) (models.Individual, error) {
+	var patched models.Individual
+	if err := h.checkIfMatch(c, "Individual", existing.ID, existing.Version); err != nil {
+		return patched, err
+	}
+
+	// Changing or testing an attribute the caller cannot see would disclose
+	// it, or overwrite it with the masked value
+	paths, err := patch.Paths(contentType, body)
+	if err != nil {
+		return patched, err
+	}
+	for _, path := range paths {
+		if !h.Masking.Visible("individual", roles(c), path) {
+			return patched, apierror.Forbidden(fmt.Sprintf("Patching %s is not permitted", path))
+		}
+	}
+
+	doc, err := json.Marshal(existing)
+	if err != nil {
+		return patched, err
+	}
+	out, err := patch.Apply(contentType, doc, body)
+	if err != nil {
+		return patched, err
+	}
+	if err := patch.CheckReadOnly(doc, out, individualReadOnly); err != nil {
+		return patched, err
+	}
+
+	if err := json.Unmarshal(out, &patched); err != nil {
+		return patched, fmt.Errorf("%w: %v", patch.ErrNotApplicable, err)
+	}
+	patched.ID = existing.ID
+	patched.ModificationDate = time.Now()
+	patched.Version = existing.Version + 1
+	if subject := author(c); subject != "" {
+		patched.ModifiedBy = subject
+	}
+	// Entries added to sub-resource arrays get server-generated ids; the
+	// others must be ones the individual already has
+	if err := h.assignIDs(&patched, &existing); err != nil {
+		return patched, err
+	}
+	if err := c.Validate(&patched); err != nil {
+		return patched, err
+	}
+	return patched, nil
+}
@@ -0,0 +1,58 @@
+// internal/handlers/patch_test.go
+package handlers
+
+import (
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/auth"
+	"github.com/your-username/tmf632-service/internal/masking"
+	"github.com/your-username/tmf632-service/internal/patch"
+)
+
+func TestPatchMaskedAttributes(t *testing.T) {
+	h, e := newTestHandler(t)
+	h.Masking = masking.Policy{"individual": {
+		{Path: "contactMedium.phoneNumber", Default: masking.Mask, Keep: 4, Roles: map[string]masking.Action{"kyc": masking.Reveal}},
+		{Path: "familyName", Default: masking.Hide, Roles: map[string]masking.Action{"kyc": masking.Reveal}},
+	}}
+	created := serve(e, h.CreateIndividual, request{
+		method: "POST",
+		body:   `{"id":"pm","givenName":"Ann","familyName":"Secret","contactMedium":[{"mediumType":"phone","phoneNumber":"+3161234"}]}`,
+	})
+	if created.Code != 201 {
+		t.Fatalf("create: %d %s", created.Code, created.Body)
+	}
+
+	jsonPatch, mergePatch := patch.JSONPatchContentType, patch.MergePatchContentType
+	tests := []struct {
+		roles       []string
+		contentType string
+		body        string
+		code        int
+	}{
+		// A test operation would reveal a hidden value one guess at a time
+		{nil, jsonPatch, `[{"op":"test","path":"/familyName","value":"Secret"}]`, 403},
+		{nil, jsonPatch, `[{"op":"test","path":"/contactMedium/0/phoneNumber","value":"+3161234"}]`, 403},
+		{nil, jsonPatch, `[{"op":"copy","from":"/familyName","path":"/givenName"}]`, 403},
+		{nil, jsonPatch, `[{"op":"replace","path":"","value":{}}]`, 403},
+		{nil, jsonPatch, `[{"op":"add","path":"/contactMedium/-","value":{"mediumType":"phone"}}]`, 403},
+		{nil, mergePatch, `{"contactMedium":[]}`, 403},
+		{nil, mergePatch, `{"familyName":null}`, 403},
+		{nil, mergePatch, `{"givenName":"Bob"}`, 200},
+		{nil, jsonPatch, `[{"op":"replace","path":"/givenName","value":"Cy"}]`, 200},
+		{[]string{"kyc"}, jsonPatch, `[{"op":"test","path":"/familyName","value":"Secret"}]`, 200},
+		{[]string{"kyc"}, mergePatch, `{"contactMedium":[]}`, 200},
+	}
+	for _, tt := range tests {
+		rec := serve(e, h.PatchIndividual, request{
+			method:      "PATCH",
+			id:          "pm",
+			contentType: tt.contentType,
+			body:        tt.body,
+			principal:   &auth.Principal{Subject: "x", Roles: tt.roles},
+		})
+		if rec.Code != tt.code {
+			t.Errorf("%v %s: got %d, want %d: %s", tt.roles, tt.body, rec.Code, tt.code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// internal/health/checks.go
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/migrate"
+)
+
+// Database pings db and reports the round trip time.
+func Database(db *sql.DB) Check {
+	return Check{
+		Name: "database:responseTime",
+		Run: func(ctx context.Context) Result {
+			start := time.Now()
+			err := db.PingContext(ctx)
+			result := Result{
+				ComponentType: "datastore",
+				ObservedValue: time.Since(start).Milliseconds(),
+				ObservedUnit:  "ms",
+				Status:        Pass,
+				Time:          time.Now(),
+			}
+			if err != nil {
+				result.Status, result.Output = Fail, err.Error()
+			}
+			return result
+		},
+	}
+}
+
+// Migrations compares the recorded schema version with the latest one this
+// build ships. A schema that is behind or dirty fails; one that is ahead
+// only warns, as during a rolling deploy the new replicas migrate first and
+// migrations are written to stay compatible with the previous release.
+func Migrations(m *migrate.Migrator) Check {
+	return Check{
+		Name: "database:schemaVersion",
+		Run: func(ctx context.Context) Result {
+			result := Result{ComponentType: "datastore", Status: Pass, Time: time.Now()}
+			version, dirty, err := m.Version(ctx)
+			switch {
+			case err != nil:
+				result.Status, result.Output = Fail, err.Error()
+			case dirty:
+				result.Status, result.Output = Fail, fmt.Sprintf("migration %d failed and needs repair", version)
+			case version < m.Latest():
+				result.Status, result.Output = Fail, fmt.Sprintf("expected version %d, run the pending migrations", m.Latest())
+			case version > m.Latest():
+				result.Status, result.Output = Warn, fmt.Sprintf("expected version %d, the schema is newer than this build", m.Latest())
+			}
+			result.ObservedValue = version
+			return result
+		},
+	}
+}
+
+// Backlogger reports how many events wait for delivery.
+type Backlogger interface {
+	Backlog(ctx context.Context) (int64, error)
+}
+
+// EventBacklog reports the undelivered events of b and warns once there
+// are more than warnAt. A backlog alone never makes the instance unready:
+// taking it out of rotation would not deliver events any faster.
+func EventBacklog(b Backlogger, warnAt int64) Check {
+	return Check{
+		Name: "events:backlog",
+		Run: func(ctx context.Context) Result {
+			result := Result{ComponentType: "component", ObservedUnit: "events", Status: Pass, Time: time.Now()}
+			n, err := b.Backlog(ctx)
+			switch {
+			case err != nil:
+				result.Status, result.Output = Fail, err.Error()
+			case n > warnAt:
+				result.Status, result.Output = Warn, fmt.Sprintf("more than %d events wait for delivery", warnAt)
+			}
+			result.ObservedValue = n
+			return result
+		},
+	}
+}
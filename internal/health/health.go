@@ -0,0 +1,157 @@
+// internal/health/health.go
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MediaType is the content type of the IETF health check response format
+// (draft-inadarei-api-health-check).
+const MediaType = "application/health+json"
+
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// worse reports whether s is a worse outcome than other.
+func (s Status) worse(other Status) bool {
+	rank := map[Status]int{Pass: 0, Warn: 1, Fail: 2}
+	return rank[s] > rank[other]
+}
+
+// Response is the health+json body returned by every probe.
+type Response struct {
+	Status      Status              `json:"status"`
+	ServiceID   string              `json:"serviceId,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Output      string              `json:"output,omitempty"`
+	Checks      map[string][]Result `json:"checks,omitempty"`
+}
+
+// Result is the state of one component, an entry of Response.Checks.
+type Result struct {
+	ComponentType string      `json:"componentType,omitempty"`
+	ObservedValue interface{} `json:"observedValue,omitempty"`
+	ObservedUnit  string      `json:"observedUnit,omitempty"`
+	Status        Status      `json:"status"`
+	Time          time.Time   `json:"time"`
+	Output        string      `json:"output,omitempty"`
+}
+
+// Check inspects one dependency. Name is its key in Response.Checks, in the
+// draft's "component:measurement" form, and Run must honour ctx.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// Health serves the liveness, readiness and startup probes. Liveness never
+// looks at dependencies, so an unreachable database makes the instance
+// unready rather than getting it restarted.
+type Health struct {
+	ServiceID string
+	// Timeout bounds each check run for a readiness probe.
+	Timeout time.Duration
+
+	checks   []Check
+	started  atomic.Bool
+	draining atomic.Bool
+}
+
+func New(serviceID string, timeout time.Duration) *Health {
+	return &Health{ServiceID: serviceID, Timeout: timeout}
+}
+
+// Register adds checks to the readiness probe.
+func (h *Health) Register(checks ...Check) {
+	h.checks = append(h.checks, checks...)
+}
+
+// Started marks startup complete: the schema is migrated and the
+// background workers run.
+func (h *Health) Started() {
+	h.started.Store(true)
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing
+// to the instance before its listener closes.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Live answers GET /health/live. It passes as long as the process serves
+// requests.
+func (h *Health) Live(c echo.Context) error {
+	return h.respond(c, Response{Status: Pass, Description: "liveness"})
+}
+
+// Startup answers GET /health/startup.
+func (h *Health) Startup(c echo.Context) error {
+	if !h.started.Load() {
+		return h.respond(c, Response{Status: Fail, Description: "startup", Output: "starting"})
+	}
+	return h.respond(c, Response{Status: Pass, Description: "startup"})
+}
+
+// Ready answers GET /health/ready by running every registered check
+// concurrently. A failing check makes the instance unready; a warning is
+// reported but still passes the probe.
+func (h *Health) Ready(c echo.Context) error {
+	resp := Response{Status: Pass, Description: "readiness"}
+	switch {
+	case h.draining.Load():
+		resp.Status, resp.Output = Fail, "shutting down"
+		return h.respond(c, resp)
+	case !h.started.Load():
+		resp.Status, resp.Output = Fail, "starting"
+		return h.respond(c, resp)
+	}
+
+	results := make([]Result, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Request().Context(), h.Timeout)
+			defer cancel()
+			results[i] = check.Run(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	resp.Checks = make(map[string][]Result, len(h.checks))
+	for i, check := range h.checks {
+		resp.Checks[check.Name] = append(resp.Checks[check.Name], results[i])
+		if results[i].Status.worse(resp.Status) {
+			resp.Status = results[i].Status
+		}
+	}
+	return h.respond(c, resp)
+}
+
+func (h *Health) respond(c echo.Context, resp Response) error {
+	resp.ServiceID = h.ServiceID
+
+	status := http.StatusOK
+	if resp.Status == Fail {
+		status = http.StatusServiceUnavailable
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Blob(status, MediaType, body)
+}
@@ -0,0 +1,172 @@
+// internal/health/health_test.go
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/labstack/echo/v4"
+	"github.com/your-username/tmf632-service/internal/migrate"
+	"go.uber.org/zap"
+)
+
+// probe calls fn and returns the status code and decoded body.
+func probe(t *testing.T, fn echo.HandlerFunc) (int, Response) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/health", nil), rec)
+	if err := fn(c); err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != MediaType {
+		t.Errorf("content type %q", ct)
+	}
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, resp
+}
+
+// fixed is a check with a constant outcome.
+func fixed(name string, status Status) Check {
+	return Check{Name: name, Run: func(context.Context) Result { return Result{Status: status} }}
+}
+
+func TestProbes(t *testing.T) {
+	h := New("tmf632", 50*time.Millisecond)
+	h.Register(fixed("a:x", Pass))
+
+	if code, resp := probe(t, h.Live); code != http.StatusOK || resp.Status != Pass || resp.ServiceID != "tmf632" {
+		t.Errorf("live: %d %+v", code, resp)
+	}
+	for name, fn := range map[string]echo.HandlerFunc{"startup": h.Startup, "ready": h.Ready} {
+		if code, resp := probe(t, fn); code != http.StatusServiceUnavailable || resp.Output != "starting" {
+			t.Errorf("%s before start: %d %+v", name, code, resp)
+		}
+	}
+
+	h.Started()
+	if code, _ := probe(t, h.Startup); code != http.StatusOK {
+		t.Errorf("startup: %d", code)
+	}
+	if code, resp := probe(t, h.Ready); code != http.StatusOK || resp.Status != Pass || len(resp.Checks["a:x"]) != 1 {
+		t.Errorf("ready: %d %+v", code, resp)
+	}
+
+	// A warning is reported without failing the probe
+	h.Register(fixed("b:x", Warn))
+	if code, resp := probe(t, h.Ready); code != http.StatusOK || resp.Status != Warn {
+		t.Errorf("ready with warning: %d %+v", code, resp)
+	}
+
+	// A check that runs over the timeout sees its context end
+	h.Register(Check{Name: "c:x", Run: func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Result{Status: Fail, Output: ctx.Err().Error()}
+	}})
+	code, resp := probe(t, h.Ready)
+	if code != http.StatusServiceUnavailable || resp.Status != Fail || resp.Checks["c:x"][0].Output != context.DeadlineExceeded.Error() {
+		t.Errorf("ready with failure: %d %+v", code, resp)
+	}
+
+	h.Drain()
+	if code, resp := probe(t, h.Ready); code != http.StatusServiceUnavailable || resp.Output != "shutting down" {
+		t.Errorf("ready while draining: %d %+v", code, resp)
+	}
+	if code, _ := probe(t, h.Live); code != http.StatusOK {
+		t.Errorf("live while draining: %d", code)
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite", t.TempDir()+"/health.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := Database(db)
+	if result := check.Run(context.Background()); result.Status != Pass || result.ObservedUnit != "ms" {
+		t.Errorf("open database: %+v", result)
+	}
+	db.Close()
+	if result := check.Run(context.Background()); result.Status != Fail || result.Output == "" {
+		t.Errorf("closed database: %+v", result)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", t.TempDir()+"/health.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	newMigrator := func(versions ...string) *migrate.Migrator {
+		fsys := fstest.MapFS{}
+		for _, v := range versions {
+			fsys[v+"_m.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			fsys[v+"_m.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		}
+		m, err := migrate.New(db, fsys, migrate.SQLite, zap.NewNop().Sugar())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	current := newMigrator("000001", "000002")
+	if result := Migrations(current).Run(ctx); result.Status != Fail {
+		t.Errorf("pending migrations: %+v", result)
+	}
+	if err := current.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if result := Migrations(current).Run(ctx); result.Status != Pass || result.ObservedValue != uint64(2) {
+		t.Errorf("migrated: %+v", result)
+	}
+	// A build one release behind the schema only warns
+	if result := Migrations(newMigrator("000001")).Run(ctx); result.Status != Warn {
+		t.Errorf("newer schema: %+v", result)
+	}
+
+	if _, err := db.Exec("UPDATE schema_migrations SET dirty = true"); err != nil {
+		t.Fatal(err)
+	}
+	if result := Migrations(current).Run(ctx); result.Status != Fail {
+		t.Errorf("dirty schema: %+v", result)
+	}
+}
+
+// backlog is a Backlogger with a fixed answer.
+type backlog struct {
+	n   int64
+	err error
+}
+
+func (b backlog) Backlog(context.Context) (int64, error) {
+	return b.n, b.err
+}
+
+func TestEventBacklog(t *testing.T) {
+	tests := []struct {
+		backlog backlog
+		want    Status
+	}{
+		{backlog{n: 10}, Pass},
+		{backlog{n: 100}, Pass},
+		{backlog{n: 101}, Warn},
+		{backlog{err: errors.New("no database")}, Fail},
+	}
+	for _, tt := range tests {
+		if result := EventBacklog(tt.backlog, 100).Run(context.Background()); result.Status != tt.want {
+			t.Errorf("%+v: got %+v, want %s", tt.backlog, result, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// internal/idempotency/idempotency.go
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HeaderKey is the request header carrying the client's idempotency key.
+const HeaderKey = "Idempotency-Key"
+
+var (
+	// ErrKeyReused is returned when a key is sent again with a different
+	// request payload.
+	ErrKeyReused = errors.New("idempotency key was already used with a different payload")
+	// ErrInProgress is returned when a key is claimed but has no stored
+	// response yet.
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Response is the stored outcome of the first request made with a key.
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// Hash fingerprints a request payload. JSON bodies are compared by value,
+// so whitespace and key order do not matter.
+func Hash(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		body, _ = json.Marshal(v)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Claim reserves key for scope within tx. It returns nil when the caller
+// now owns the key and must store its response with Complete before tx
+// commits. If the key already completed it returns the stored response.
+//
+// The claim is an insert into the primary key, so a concurrent request
+// with the same key blocks until the owner's transaction finishes and then
+// sees its stored response.
+func Claim(tx *gorm.DB, scope, key, hash string, ttl time.Duration) (*Response, error) {
+	id := models.IdempotencyKey{Scope: scope, Key: key}
+	now := time.Now()
+
+	// An expired key may be reused as if it were new
+	if err := tx.Where(&id).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, err
+	}
+
+	row := models.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: hash,
+		ExpiresAt:   now.Add(ttl),
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var stored models.IdempotencyKey
+	if err := tx.Where(&id).First(&stored).Error; err != nil {
+		return nil, err
+	}
+	if stored.RequestHash != hash {
+		return nil, ErrKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, ErrInProgress
+	}
+
+	resp := &Response{StatusCode: stored.StatusCode, Body: []byte(stored.Body)}
+	if stored.Headers != "" {
+		if err := json.Unmarshal([]byte(stored.Headers), &resp.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// Complete stores resp as the response for a key claimed in tx.
+func Complete(tx *gorm.DB, scope, key string, resp Response) error {
+	headers, err := json.Marshal(resp.Headers)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.IdempotencyKey{}).
+		Where(&models.IdempotencyKey{Scope: scope, Key: key}).
+		Updates(map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"headers":     string(headers),
+			"body":        string(resp.Body),
+		}).Error
+}
+
+// Janitor periodically deletes expired keys.
+type Janitor struct {
+	db       *gorm.DB
+	logger   *zap.SugaredLogger
+	interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewJanitor(db *gorm.DB, logger *zap.SugaredLogger, interval time.Duration) *Janitor {
+	return &Janitor{db: db, logger: logger, interval: interval}
+}
+
+// Start launches the cleanup loop.
+func (j *Janitor) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.wg.Add(1)
+	go j.run(ctx)
+}
+
+// Stop cancels the cleanup loop and waits for it to exit.
+func (j *Janitor) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.wg.Wait()
+}
+
+func (j *Janitor) run(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result := j.db.WithContext(tenant.System(ctx)).Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+		if result.Error != nil && !errors.Is(result.Error, context.Canceled) {
+			j.logger.Errorw("Failed to purge expired idempotency keys", "error", result.Error)
+		} else if result.RowsAffected > 0 {
+			j.logger.Infow("Purged expired idempotency keys", "count", result.RowsAffected)
+		}
+	}
+}
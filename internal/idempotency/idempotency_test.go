@@ -0,0 +1,115 @@
+// internal/idempotency/idempotency_test.go
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/database"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newDB returns a migrated SQLite database.
+func newDB(t *testing.T) *gorm.DB {
+	db, err := database.Initialize(config.Database{Driver: "sqlite", Path: t.TempDir() + "/tmf632.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := database.NewMigrator(db, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.GormPlugin(false)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestHash(t *testing.T) {
+	if idempotency.Hash([]byte(`{"a":1,"b":[2]}`)) != idempotency.Hash([]byte(` { "b" : [2], "a" : 1 } `)) {
+		t.Error("equal JSON documents hash differently")
+	}
+	if idempotency.Hash([]byte(`{"a":1}`)) == idempotency.Hash([]byte(`{"a":2}`)) {
+		t.Error("different JSON documents hash alike")
+	}
+}
+
+func TestClaim(t *testing.T) {
+	db := newDB(t).WithContext(tenant.NewContext(context.Background(), tenant.Default))
+	hash := idempotency.Hash([]byte(`{"givenName":"Ann"}`))
+
+	if resp, err := idempotency.Claim(db, "individual", "k1", hash, time.Hour); resp != nil || err != nil {
+		t.Fatalf("first claim: got %v, %v", resp, err)
+	}
+	if _, err := idempotency.Claim(db, "individual", "k1", hash, time.Hour); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Errorf("claim before completion: got %v, want ErrInProgress", err)
+	}
+
+	stored := idempotency.Response{StatusCode: 201, Headers: map[string]string{"Location": "/individual/i1"}, Body: []byte(`{"id":"i1"}`)}
+	if err := idempotency.Complete(db, "individual", "k1", stored); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := idempotency.Claim(db, "individual", "k1", hash, time.Hour)
+	if err != nil || resp == nil || resp.StatusCode != 201 || resp.Headers["Location"] != "/individual/i1" || string(resp.Body) != `{"id":"i1"}` {
+		t.Errorf("replay: got %+v, %v", resp, err)
+	}
+	if _, err := idempotency.Claim(db, "individual", "k1", idempotency.Hash([]byte(`{}`)), time.Hour); !errors.Is(err, idempotency.ErrKeyReused) {
+		t.Errorf("other payload: got %v, want ErrKeyReused", err)
+	}
+
+	// Scopes keep the same key apart, and an expired key is claimed anew
+	if resp, err := idempotency.Claim(db, "organization", "k1", hash, time.Hour); resp != nil || err != nil {
+		t.Errorf("other scope: got %v, %v", resp, err)
+	}
+	if _, err := idempotency.Claim(db, "individual", "k2", hash, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := idempotency.Claim(db, "individual", "k2", idempotency.Hash([]byte(`{}`)), time.Hour); resp != nil || err != nil {
+		t.Errorf("expired key: got %v, %v", resp, err)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+	for _, key := range []models.IdempotencyKey{
+		{TenantID: "t1", Scope: "individual", Key: "expired", ExpiresAt: time.Now().Add(-time.Minute)},
+		{TenantID: "t2", Scope: "individual", Key: "expired", ExpiresAt: time.Now().Add(-time.Minute)},
+		{TenantID: "t1", Scope: "individual", Key: "live", ExpiresAt: time.Now().Add(time.Hour)},
+	} {
+		if err := db.WithContext(tenant.NewContext(ctx, key.TenantID)).Create(&key).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	janitor := idempotency.NewJanitor(db, zap.NewNop().Sugar(), time.Millisecond)
+	janitor.Start(ctx)
+	defer janitor.Stop()
+
+	// Expired keys of every tenant go, live ones stay
+	system := db.WithContext(tenant.System(ctx))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var keys []models.IdempotencyKey
+		if err := system.Find(&keys).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) == 1 && keys[0].Key == "live" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("keys left: %+v", keys)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
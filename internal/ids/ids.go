@@ -0,0 +1,32 @@
+// internal/ids/ids.go
+package ids
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+// Supported ID strategies.
+const (
+	StrategyUUID = "uuid"
+	StrategyULID = "ulid"
+)
+
+// Generator returns a new unique identifier on each call. Generators are
+// safe for concurrent use.
+type Generator func() string
+
+// New returns the Generator for strategy. An empty strategy selects UUIDs.
+func New(strategy string) (Generator, error) {
+	switch strategy {
+	case "", StrategyUUID:
+		return uuid.NewString, nil
+	case StrategyULID:
+		// ulid.Make is monotonic within a millisecond, so ids sort in
+		// creation order.
+		return func() string { return ulid.Make().String() }, nil
+	}
+	return nil, fmt.Errorf("unknown id strategy %q, expected %s or %s", strategy, StrategyUUID, StrategyULID)
+}
@@ -2,14 +2,24 @@
 package logger
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-func NewLogger() (*zap.Logger, error) {
+// NewLogger builds the service logger. level is debug, info, warn or error
+// and format is json, or console for human-readable local output.
+func NewLogger(level, format string) (*zap.Logger, error) {
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
 	config := zap.Config{
-		Encoding:         "json",
-		Level:            zap.NewAtomicLevelAt(zapcore.InfoLevel),
+		Encoding:         format,
+		Level:            atomicLevel,
 		OutputPaths:      []string{"stdout"},
 		ErrorOutputPaths: []string{"stderr"},
 		EncoderConfig: zapcore.EncoderConfig{
@@ -24,3 +34,13 @@ func NewLogger() (*zap.Logger, error) {
 
 	return config.Build()
 }
+
+// WithTrace returns logger annotated with the trace and span ids of the
+// span in ctx, so log lines can be found from a trace and vice versa.
+func WithTrace(ctx context.Context, logger *zap.SugaredLogger) *zap.SugaredLogger {
+	span := trace.SpanContextFromContext(ctx)
+	if !span.IsValid() {
+		return logger
+	}
+	return logger.With("trace_id", span.TraceID().String(), "span_id", span.SpanID().String())
+}
@@ -0,0 +1,312 @@
+// internal/masking/masking.go
+package masking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Action decides how much of an attribute a caller sees.
+type Action string
+
+const (
+	Hide   Action = "hide"
+	Mask   Action = "mask"
+	Reveal Action = "reveal"
+)
+
+// rank orders the actions from least to most revealing.
+var rank = map[Action]int{Hide: 0, Mask: 1, Reveal: 2}
+
+// Rule shapes one attribute of a resource. Path is the dotted JSON path of
+// the attribute, such as "individualIdentification.identificationId";
+// arrays along the way apply the rule to every element. Callers get the
+// most revealing action of their roles, or Default when none is listed.
+type Rule struct {
+	Path    string            `yaml:"path"`
+	Default Action            `yaml:"default"`
+	Roles   map[string]Action `yaml:"roles"`
+	// Keep is the number of trailing characters Mask leaves visible.
+	Keep int `yaml:"keep"`
+}
+
+// Policy lists the rules per resource type, such as "individual".
+type Policy map[string][]Rule
+
+// Validate reports rules with an empty path or an unknown action.
+func (p Policy) Validate() error {
+	var errs []error
+	for resource, rules := range p {
+		for _, rule := range rules {
+			if rule.Path == "" {
+				errs = append(errs, fmt.Errorf("%s: rule without a path", resource))
+			}
+			if _, ok := rank[rule.Default]; !ok {
+				errs = append(errs, fmt.Errorf("%s %s: unknown default %q, use hide, mask or reveal", resource, rule.Path, rule.Default))
+			}
+			for role, action := range rule.Roles {
+				if _, ok := rank[action]; !ok {
+					errs = append(errs, fmt.Errorf("%s %s: role %q has unknown action %q", resource, rule.Path, role, action))
+				}
+			}
+			if rule.Keep < 0 {
+				errs = append(errs, fmt.Errorf("%s %s: keep must not be negative", resource, rule.Path))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shape returns v, a resource or a list of resources, as a JSON document
+// with the attributes roles may not see hidden or masked. v is returned
+// unchanged when the policy has no rules for resource.
+func (p Policy) Shape(resource string, roles []string, v interface{}) (interface{}, error) {
+	rules := p[resource]
+	if len(rules) == 0 {
+		return v, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		action := rule.actionFor(roles)
+		if action == Reveal {
+			continue
+		}
+		apply(doc, strings.Split(rule.Path, "."), action, rule.Keep)
+	}
+	return doc, nil
+}
+
+func (r Rule) actionFor(roles []string) Action {
+	action, matched := r.Default, false
+	for _, role := range roles {
+		granted, ok := r.Roles[role]
+		if !ok {
+			continue
+		}
+		if !matched || rank[granted] > rank[action] {
+			action, matched = granted, true
+		}
+	}
+	return action
+}
+
+// apply hides or masks the attribute at path below doc. Attribute names
+// may themselves contain dots, so the longest name present is matched
+// first.
+func apply(doc interface{}, path []string, action Action, keep int) {
+	switch node := doc.(type) {
+	case []interface{}:
+		for _, item := range node {
+			apply(item, path, action, keep)
+		}
+	case map[string]interface{}:
+		for i := len(path); i > 0; i-- {
+			name := strings.Join(path[:i], ".")
+			value, ok := node[name]
+			if !ok {
+				continue
+			}
+			if i < len(path) {
+				apply(value, path[i:], action, keep)
+				return
+			}
+			if masked, ok := mask(value, keep); ok && action == Mask {
+				node[name] = masked
+			} else {
+				delete(node, name)
+			}
+			return
+		}
+	}
+}
+
+// mask replaces all but the last keep characters of a scalar with '*'.
+// Objects and arrays cannot be masked.
+func mask(value interface{}, keep int) (string, bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64, bool:
+		s = fmt.Sprint(v)
+	default:
+		return "", false
+	}
+
+	// Values no longer than keep would be left whole, so they are
+	// masked completely
+	runes := []rune(s)
+	hidden := len(runes) - keep
+	if hidden <= 0 {
+		hidden = len(runes)
+	}
+	for i := 0; i < hidden; i++ {
+		runes[i] = '*'
+	}
+	return string(runes), true
+}
+
+// Visible reports whether roles see the attribute at path of resource in
+// full, counting an attribute as hidden when anything in it or around it
+// is; the empty path is the whole resource. Filtering on an attribute that
+// is not visible would disclose it one guess at a time.
+func (p Policy) Visible(resource string, roles []string, path string) bool {
+	for _, rule := range p[resource] {
+		if rule.actionFor(roles) == Reveal {
+			continue
+		}
+		if path == "" || path == rule.Path || strings.HasPrefix(rule.Path, path+".") || strings.HasPrefix(path, rule.Path+".") {
+			return false
+		}
+	}
+	return true
+}
+
+// ConcealedError is returned by Restore for a document that changes Path,
+// an attribute the caller cannot see in full.
+type ConcealedError struct {
+	Path string
+}
+
+func (e *ConcealedError) Error() string {
+	return "changes concealed attribute " + e.Path
+}
+
+// Restore takes body, a JSON document replacing stored, and puts back the
+// attributes of stored that roles may not see in full, so that a caller
+// writing back what it read cannot overwrite them with their masked form
+// or drop them when hidden. Entries of arrays are matched by their id.
+// Where body shows such an attribute other than Shape would have, Restore
+// fails with a ConcealedError. body is returned unchanged when roles see
+// everything.
+func (p Policy) Restore(resource string, roles []string, body []byte, stored interface{}) ([]byte, error) {
+	var concealed []Rule
+	for _, rule := range p[resource] {
+		if rule.actionFor(roles) != Reveal {
+			concealed = append(concealed, rule)
+		}
+	}
+	if len(concealed) == 0 {
+		return body, nil
+	}
+
+	var sent map[string]interface{}
+	if err := decode(body, &sent); err != nil {
+		return nil, err
+	}
+	shown, err := p.Shape(resource, roles, stored)
+	if err != nil {
+		return nil, err
+	}
+	var storedDoc, shownDoc interface{}
+	if err := remarshal(stored, &storedDoc); err != nil {
+		return nil, err
+	}
+	if err := remarshal(shown, &shownDoc); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range concealed {
+		if err := restore(sent, storedDoc, shownDoc, strings.Split(rule.Path, "."), rule.Path); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(sent)
+}
+
+// restore puts the attribute at path below stored into sent, provided sent
+// has it as shown does. Attribute names are matched as apply does.
+func restore(sent, stored, shown interface{}, path []string, full string) error {
+	switch node := sent.(type) {
+	case []interface{}:
+		for _, item := range node {
+			if err := restore(item, byID(stored, item), byID(shown, item), path, full); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		storedNode, _ := stored.(map[string]interface{})
+		shownNode, _ := shown.(map[string]interface{})
+		for i := len(path); i > 0; i-- {
+			name := strings.Join(path[:i], ".")
+			_, inSent := node[name]
+			storedValue, inStored := storedNode[name]
+			if !inSent && !inStored {
+				continue
+			}
+			if i < len(path) {
+				return restore(node[name], storedValue, shownNode[name], path[i:], full)
+			}
+			if !equal(node[name], shownNode[name]) {
+				return &ConcealedError{Path: full}
+			}
+			if inStored {
+				node[name] = storedValue
+			} else {
+				delete(node, name)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// byID returns the entry of the array items with the id of entry, or nil.
+func byID(items, entry interface{}) interface{} {
+	list, _ := items.([]interface{})
+	object, _ := entry.(map[string]interface{})
+	id, _ := object["id"].(string)
+	if id == "" {
+		return nil
+	}
+	for _, item := range list {
+		if candidate, _ := item.(map[string]interface{}); candidate["id"] == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// equal compares decoded JSON values, whatever form their numbers take.
+func equal(a, b interface{}) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	if errX != nil || errY != nil {
+		return false
+	}
+	var vx, vy interface{}
+	if json.Unmarshal(x, &vx) != nil || json.Unmarshal(y, &vy) != nil {
+		return false
+	}
+	return reflect.DeepEqual(vx, vy)
+}
+
+// remarshal converts v to its decoded JSON form.
+func remarshal(v interface{}, out *interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return decode(raw, out)
+}
+
+// decode unmarshals raw keeping numbers as written, so that restoring an
+// attribute does not round any other.
+func decode(raw []byte, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(out)
+}
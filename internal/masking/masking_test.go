@@ -0,0 +1,171 @@
+// internal/masking/masking_test.go
+package masking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var policy = Policy{"individual": {
+	{Path: "individualIdentification.identificationId", Default: Hide, Keep: 4, Roles: map[string]Action{"call-centre": Mask, "kyc": Reveal}},
+	{Path: "contactMedium.street1", Default: Hide, Roles: map[string]Action{"kyc": Reveal}},
+}}
+
+const party = `{"id":"a","givenName":"Ann",
+	"individualIdentification":[{"id":"p","identificationId":"P1234567"},{"id":"q","identificationId":"12"}],
+	"contactMedium":[{"id":"m","street1":"Main Street 1","city":"Utrecht"}]}`
+
+func decoded(t *testing.T, doc string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	invalid := Policy{"individual": {
+		{Path: "", Default: Hide},
+		{Path: "givenName", Default: "blur"},
+		{Path: "familyName", Default: Hide, Roles: map[string]Action{"kyc": "show"}},
+		{Path: "title", Default: Mask, Keep: -1},
+	}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		roles []string
+		want  string
+	}{
+		{nil, `{"id":"a","givenName":"Ann",
+			"individualIdentification":[{"id":"p"},{"id":"q"}],
+			"contactMedium":[{"id":"m","city":"Utrecht"}]}`},
+		// Values no longer than keep are masked completely
+		{[]string{"call-centre"}, `{"id":"a","givenName":"Ann",
+			"individualIdentification":[{"id":"p","identificationId":"****4567"},{"id":"q","identificationId":"**"}],
+			"contactMedium":[{"id":"m","city":"Utrecht"}]}`},
+		// The most revealing action of the roles wins
+		{[]string{"call-centre", "kyc"}, party},
+	}
+	for _, tt := range tests {
+		got, err := policy.Shape("individual", tt.roles, decoded(t, party))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, decoded(t, tt.want)) {
+			t.Errorf("%v: got %v", tt.roles, got)
+		}
+	}
+
+	if got, _ := policy.Shape("organization", nil, "unchanged"); got != "unchanged" {
+		t.Errorf("resource without rules: got %v", got)
+	}
+}
+
+func TestVisible(t *testing.T) {
+	tests := []struct {
+		roles   []string
+		path    string
+		visible bool
+	}{
+		{nil, "givenName", true},
+		{nil, "contactMedium.city", true},
+		{nil, "contactMedium.street1", false},
+		{nil, "contactMedium", false},
+		{nil, "contactMedium.street1.x", false},
+		{nil, "", false},
+		{[]string{"kyc"}, "", true},
+		{[]string{"call-centre"}, "individualIdentification.identificationId", false},
+	}
+	for _, tt := range tests {
+		if got := policy.Visible("individual", tt.roles, tt.path); got != tt.visible {
+			t.Errorf("%v %q: got %v", tt.roles, tt.path, got)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	stored := decoded(t, party)
+	tests := []struct {
+		name  string
+		roles []string
+		body  string
+		want  string
+		path  string
+	}{
+		{
+			"masked and hidden values written back",
+			[]string{"call-centre"},
+			`{"id":"a","givenName":"Anne","big":12345678901234567890,
+				"individualIdentification":[{"id":"p","identificationId":"****4567"}],
+				"contactMedium":[{"id":"m","city":"Amersfoort"},{"city":"Zeist"}]}`,
+			`{"id":"a","givenName":"Anne","big":12345678901234567890,
+				"individualIdentification":[{"id":"p","identificationId":"P1234567"}],
+				"contactMedium":[{"id":"m","street1":"Main Street 1","city":"Amersfoort"},{"city":"Zeist"}]}`,
+			"",
+		},
+		{
+			"everything visible",
+			[]string{"kyc"},
+			`{"id":"a","contactMedium":[{"id":"m","street1":"Elsewhere"}]}`,
+			`{"id":"a","contactMedium":[{"id":"m","street1":"Elsewhere"}]}`,
+			"",
+		},
+		{
+			"masked value changed",
+			[]string{"call-centre"},
+			`{"individualIdentification":[{"id":"p","identificationId":"X0000000"}]}`,
+			"",
+			"individualIdentification.identificationId",
+		},
+		{
+			"hidden value set",
+			nil,
+			`{"contactMedium":[{"id":"m","street1":"Elsewhere"}]}`,
+			"",
+			"contactMedium.street1",
+		},
+		{
+			"hidden value set on a new entry",
+			nil,
+			`{"contactMedium":[{"street1":"Elsewhere"}]}`,
+			"",
+			"contactMedium.street1",
+		},
+	}
+	for _, tt := range tests {
+		got, err := policy.Restore("individual", tt.roles, []byte(tt.body), stored)
+		if tt.path != "" {
+			var concealed *ConcealedError
+			if !errors.As(err, &concealed) || concealed.Path != tt.path {
+				t.Errorf("%s: got %v, want ConcealedError for %s", tt.name, err, tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !equal(decoded(t, string(got)), decoded(t, tt.want)) {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+		// Numbers are written back as they were sent
+		if bytes.Contains([]byte(tt.body), []byte("12345678901234567890")) && !bytes.Contains(got, []byte("12345678901234567890")) {
+			t.Errorf("%s: number rounded in %s", tt.name, got)
+		}
+	}
+
+	if _, err := policy.Restore("individual", nil, []byte(`[`), stored); err == nil {
+		t.Error("malformed body accepted")
+	}
+}
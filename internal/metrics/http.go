@@ -0,0 +1,51 @@
+// internal/metrics/http.go
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute labels requests no route matched, so arbitrary paths
+// cannot inflate the number of series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records the rate, errors and duration of every request,
+// labelled by the route template such as /individual/:id rather than the
+// raw URI. The scrape endpoint itself is skipped.
+func (m *Metrics) Middleware(skip ...string) echo.MiddlewareFunc {
+	skipped := make(map[string]bool, len(skip))
+	for _, path := range skip {
+		skipped[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipped[c.Path()] {
+				return next(c)
+			}
+
+			start := time.Now()
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			err := next(c)
+			if err != nil {
+				// Write the error response now so its status is known;
+				// the error handler skips committed responses
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			status := strconv.Itoa(c.Response().Status)
+			m.requests.WithLabelValues(c.Request().Method, route, status).Inc()
+			m.duration.WithLabelValues(c.Request().Method, route, status).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/repository"
+)
+
+// collectTimeout bounds the queries run while a scrape collects the
+// business gauges and event backlog.
+const collectTimeout = 2 * time.Second
+
+// Metrics holds the service's Prometheus collectors. They live on a
+// registry of their own, served by Handler together with the Go runtime
+// and process collectors.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests   *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	inFlight   prometheus.Gauge
+	queries    *prometheus.HistogramVec
+	deliveries *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "handler", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route template and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "handler", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests currently being served.",
+		}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database statement latency by operation and table.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "events_deliveries_total",
+			Help: "Event deliveries to hub subscribers by event type and outcome.",
+		}, []string{"event_type", "outcome"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.duration, m.inFlight, m.queries, m.deliveries,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format. A
+// collector that fails, such as a business gauge while the database is
+// down, is reported without failing the rest of the scrape.
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	}))
+}
+
+// ObserveDelivery counts the outcome of delivering evt to one subscriber.
+// It matches events.HubSink.Observe.
+func (m *Metrics) ObserveDelivery(evt events.Event, err error) {
+	outcome := "delivered"
+	if err != nil {
+		outcome = "failed"
+	}
+	m.deliveries.WithLabelValues(evt.EventType, outcome).Inc()
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Backlogger reports how many events wait for delivery.
+type Backlogger interface {
+	Backlog(ctx context.Context) (int64, error)
+}
+
+// RegisterBacklog exports the number of events b has yet to deliver.
+func (m *Metrics) RegisterBacklog(b Backlogger) {
+	m.Registry.MustRegister(&backlogCollector{
+		backlog: b,
+		desc:    prometheus.NewDesc("events_backlog", "Events waiting for delivery.", nil, nil),
+	})
+}
+
+// RegisterStats exports the number of stored parties and hub
+// subscriptions, counted at scrape time.
+func (m *Metrics) RegisterStats(repo StatsSource) {
+	m.Registry.MustRegister(&statsCollector{
+		repo:          repo,
+		individuals:   prometheus.NewDesc("tmf632_individuals", "Individuals stored.", nil, nil),
+		organizations: prometheus.NewDesc("tmf632_organizations", "Organizations stored.", nil, nil),
+		subscriptions: prometheus.NewDesc("tmf632_hub_subscriptions", "Hub subscriptions registered.", nil, nil),
+	})
+}
+
+// StatsSource is the part of repository.Repository the business gauges
+// read.
+type StatsSource interface {
+	Stats(ctx context.Context) (repository.Stats, error)
+}
+
+type statsCollector struct {
+	repo                                      StatsSource
+	individuals, organizations, subscriptions *prometheus.Desc
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.individuals
+	ch <- c.organizations
+	ch <- c.subscriptions
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stats, err := c.repo.Stats(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.individuals, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.individuals, prometheus.GaugeValue, float64(stats.Individuals))
+	ch <- prometheus.MustNewConstMetric(c.organizations, prometheus.GaugeValue, float64(stats.Organizations))
+	ch <- prometheus.MustNewConstMetric(c.subscriptions, prometheus.GaugeValue, float64(stats.Subscriptions))
+}
+
+type backlogCollector struct {
+	backlog Backlogger
+	desc    *prometheus.Desc
+}
+
+func (c *backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	n, err := c.backlog.Backlog(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n))
+}
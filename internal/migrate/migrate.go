@@ -0,0 +1,289 @@
+// internal/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// Dialects the Migrator can drive.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// lockID is the Postgres advisory lock key held while migrating, so that
+// replicas starting together apply each migration exactly once.
+const lockID int64 = 632_000_001
+
+// ErrDirty is returned when schema_migrations is marked dirty, which the
+// migrate CLI does when a migration fails part way. The schema must be
+// repaired by hand and the version recorded with Force.
+var ErrDirty = errors.New("database schema is dirty")
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one schema version with its up and down scripts.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads the migrations in fsys, ordered by version. Every version must
+// have both an up and a down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(script)
+		} else {
+			mig.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies migrations to a Postgres or SQLite database. The current
+// version is kept in schema_migrations using the golang-migrate layout, a
+// single (version, dirty) row, so the migrate CLI and this package can be
+// mixed.
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+	logger     *zap.SugaredLogger
+}
+
+func New(db *sql.DB, fsys fs.FS, dialect string, logger *zap.SugaredLogger) (*Migrator, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("unsupported migration dialect %q", dialect)
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, logger: logger}, nil
+}
+
+// Latest is the highest version available.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := m.current(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, mig.Up, int64(mig.Version)); err != nil {
+				return err
+			}
+			m.logger.Infow("Applied migration", "version", mig.Version, "name", mig.Name)
+		}
+		return nil
+	})
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := m.current(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version > current {
+				continue
+			}
+			previous := int64(-1)
+			if i > 0 {
+				previous = int64(m.migrations[i-1].Version)
+			}
+			if err := m.apply(ctx, conn, mig, mig.Down, previous); err != nil {
+				return err
+			}
+			m.logger.Infow("Reverted migration", "version", mig.Version, "name", mig.Name)
+			steps--
+		}
+		return nil
+	})
+}
+
+// Version reports the recorded schema version; 0 means none applied.
+func (m *Migrator) Version(ctx context.Context) (version uint64, dirty bool, err error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return 0, false, err
+	}
+	return readVersion(ctx, m.db)
+}
+
+// Force records version as applied and clean without running anything,
+// after a failed migration has been repaired by hand.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, int64(version), false)
+	})
+}
+
+// locked runs fn on a dedicated connection holding the advisory lock. A
+// SQLite file belongs to a single server process, so there it only relies
+// on each migration running in its own write transaction.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect != Postgres {
+		if err := m.ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", lockID)); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx is done
+		if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("SELECT pg_advisory_unlock(%d)", lockID)); err != nil {
+			m.logger.Warnw("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// current returns the applied version, refusing to continue from a dirty
+// schema.
+func (m *Migrator) current(ctx context.Context, conn *sql.Conn) (uint64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d; fix it and run migrate force", ErrDirty, version)
+	}
+	return version, nil
+}
+
+// apply runs script and records version in one transaction, so a failed
+// migration leaves neither schema changes nor a dirty version behind.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, script string, version int64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if err := setVersion(ctx, tx, version, false); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		dirty BOOLEAN NOT NULL
+	)`)
+	return err
+}
+
+func readVersion(ctx context.Context, db querier) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if version < 0 {
+		return 0, dirty, nil
+	}
+	return uint64(version), dirty, nil
+}
+
+// setVersion replaces the single schema_migrations row. A negative version
+// means no migration is applied.
+func setVersion(ctx context.Context, db execer, version int64, dirty bool) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version < 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, fmt.Sprintf("INSERT INTO schema_migrations (version, dirty) VALUES (%d, %t)", version, dirty))
+	return err
+}
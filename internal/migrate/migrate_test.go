@@ -0,0 +1,169 @@
+// internal/migrate/migrate_test.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/glebarez/go-sqlite"
+	"go.uber.org/zap"
+)
+
+var scripts = fstest.MapFS{
+	"000001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id TEXT);")},
+	"000001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	"000002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id TEXT); INSERT INTO b VALUES ('x');")},
+	"000002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	"000010_create_c.up.sql":   {Data: []byte("CREATE TABLE c (id TEXT);")},
+	"000010_create_c.down.sql": {Data: []byte("DROP TABLE c;")},
+	"README.md":                {Data: []byte("not a migration")},
+}
+
+// newMigrator returns a Migrator for fsys on an empty SQLite file.
+func newMigrator(t *testing.T, fsys fstest.MapFS) (*Migrator, *sql.DB) {
+	db, err := sql.Open("sqlite", t.TempDir()+"/migrate.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m, err := New(db, fsys, SQLite, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, db
+}
+
+// tables lists the tables of db other than schema_migrations.
+func tables(t *testing.T, db *sql.DB) []string {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name != 'schema_migrations' ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(scripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 3 || migrations[0].Version != 1 || migrations[2].Version != 10 || migrations[2].Name != "create_c" {
+		t.Errorf("got %+v", migrations)
+	}
+
+	tests := map[string]fstest.MapFS{
+		"missing down": {"000001_a.up.sql": {Data: []byte("SELECT 1;")}},
+		"two names": {
+			"000001_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"000001_b.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: loaded", name)
+		}
+	}
+
+	if _, err := New(nil, scripts, "mysql", zap.NewNop().Sugar()); err == nil {
+		t.Error("unsupported dialect accepted")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	m, db := newMigrator(t, scripts)
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if version, dirty, _ := m.Version(ctx); version != 10 || dirty || version != m.Latest() {
+		t.Errorf("after up: version %d, dirty %v", version, dirty)
+	}
+	// Up is a no-op once every migration is applied
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Down(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if version, _, _ := m.Version(ctx); version != 1 {
+		t.Errorf("after down 2: version %d", version)
+	}
+	if got := tables(t, db); len(got) != 1 || got[0] != "a" {
+		t.Errorf("after down 2: tables %v", got)
+	}
+
+	if err := m.Down(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if version, _, _ := m.Version(ctx); version != 0 {
+		t.Errorf("after down all: version %d", version)
+	}
+	if got := tables(t, db); len(got) != 0 {
+		t.Errorf("after down all: tables %v", got)
+	}
+}
+
+func TestFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	broken := fstest.MapFS{
+		"000001_create_a.up.sql":   scripts["000001_create_a.up.sql"],
+		"000001_create_a.down.sql": scripts["000001_create_a.down.sql"],
+		"000002_broken.up.sql":     {Data: []byte("CREATE TABLE b (id TEXT); INSERT INTO nowhere VALUES (1);")},
+		"000002_broken.down.sql":   {Data: []byte("DROP TABLE b;")},
+	}
+	m, db := newMigrator(t, broken)
+
+	// The failed migration is rolled back as a whole and leaves the
+	// schema clean at the previous version
+	if err := m.Up(ctx); err == nil {
+		t.Fatal("broken migration applied")
+	}
+	if version, dirty, _ := m.Version(ctx); version != 1 || dirty {
+		t.Errorf("version %d, dirty %v; want 1, clean", version, dirty)
+	}
+	if got := tables(t, db); len(got) != 1 || got[0] != "a" {
+		t.Errorf("tables %v", got)
+	}
+}
+
+func TestDirty(t *testing.T) {
+	ctx := context.Background()
+	m, db := newMigrator(t, scripts)
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// As the migrate CLI leaves it after a failure
+	if err := setVersion(ctx, db, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Errorf("up: got %v, want ErrDirty", err)
+	}
+	if err := m.Down(ctx, 1); !errors.Is(err, ErrDirty) {
+		t.Errorf("down: got %v, want ErrDirty", err)
+	}
+
+	if err := m.Force(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if version, dirty, _ := m.Version(ctx); version != 2 || dirty {
+		t.Errorf("after force: version %d, dirty %v", version, dirty)
+	}
+	// Table c still exists from before, so reapplying 10 fails
+	if err := m.Up(ctx); err == nil {
+		t.Error("reapplied migration 10 over its own table")
+	}
+}
@@ -9,36 +9,46 @@ import (
 
 type Individual struct {
 	gorm.Model
+	// ID is unique within the tenant owning the party, TenantID.
 	ID               string    `json:"id" gorm:"primaryKey"`
+	TenantID         string    `json:"-" gorm:"primaryKey"`
 	HREF             string    `json:"href,omitempty"`
 	Title            string    `json:"title,omitempty"`
-	GivenName        string    `json:"givenName"`
+	GivenName        string    `json:"givenName" validate:"required"`
 	FamilyName       string    `json:"familyName"`
-	MaritalStatus    string    `json:"maritalStatus,omitempty"`
-	Gender           string    `json:"gender,omitempty"`
+	MaritalStatus    string    `json:"maritalStatus,omitempty" validate:"omitempty,maritalstatus"`
+	Gender           string    `json:"gender,omitempty" validate:"omitempty,gender"`
 	NameType         string    `json:"nameType,omitempty"`
-	Nationality      string    `json:"nationality,omitempty"`
+	Nationality      string    `json:"nationality,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	Status           string    `json:"status,omitempty"`
 	CreationDate     time.Time `json:"creationDate"`
 	ModificationDate time.Time `json:"modificationDate"`
 	CreatedBy        string    `json:"createdBy"`
 	ModifiedBy       string    `json:"modifiedBy"`
 
-	ContactMedium            []ContactMedium            `json:"contactMedium,omitempty" gorm:"foreignKey:IndividualID"`
-	ExternalReference        []ExternalReference        `json:"externalReference,omitempty" gorm:"foreignKey:IndividualID"`
-	IndividualIdentification []IndividualIdentification `json:"individualIdentification,omitempty" gorm:"foreignKey:IndividualID"`
-	PartyCharacteristic      []PartyCharacteristic      `json:"partyCharacteristic,omitempty" gorm:"foreignKey:IndividualID"`
+	// Version is bumped on every change and exposed as the ETag.
+	Version int64 `json:"-" gorm:"not null;default:1"`
+
+	ContactMedium            []ContactMedium            `json:"contactMedium,omitempty" gorm:"foreignKey:IndividualID" validate:"dive"`
+	ExternalReference        []ExternalReference        `json:"externalReference,omitempty" gorm:"foreignKey:IndividualID" validate:"dive"`
+	IndividualIdentification []IndividualIdentification `json:"individualIdentification,omitempty" gorm:"foreignKey:IndividualID" validate:"dive"`
+	PartyCharacteristic      []PartyCharacteristic      `json:"partyCharacteristic,omitempty" gorm:"foreignKey:IndividualID" validate:"dive"`
 }
 
 type ContactMedium struct {
 	gorm.Model
 	ID           string `json:"id" gorm:"primaryKey"`
+	TenantID     string `json:"-" gorm:"primaryKey"`
 	IndividualID string
 	Type         string `json:"@type"`
-	MediumType   string `json:"mediumType"`
+	MediumType   string `json:"mediumType" validate:"required,mediumtype"`
 	Preferred    bool   `json:"preferred"`
 
 	// For PhoneContactMedium
-	PhoneNumber string `json:"phoneNumber,omitempty"`
+	PhoneNumber string `json:"phoneNumber,omitempty" validate:"omitempty,e164"`
+
+	// For EmailContactMedium
+	EmailAddress string `json:"emailAddress,omitempty" validate:"omitempty,email"`
 
 	// For GeographicAddressContactMedium
 	Street1         string `json:"street1,omitempty"`
@@ -52,6 +62,7 @@ type ContactMedium struct {
 type ExternalReference struct {
 	gorm.Model
 	ID                     string `json:"id" gorm:"primaryKey"`
+	TenantID               string `json:"-" gorm:"primaryKey"`
 	IndividualID           string
 	Name                   string `json:"name"`
 	ExternalIdentifierType string `json:"externalIdentifierType"`
@@ -61,18 +72,213 @@ type ExternalReference struct {
 type IndividualIdentification struct {
 	gorm.Model
 	ID                 string `json:"id" gorm:"primaryKey"`
+	TenantID           string `json:"-" gorm:"primaryKey"`
 	IndividualID       string
-	IdentificationType string    `json:"identificationType"`
-	IdentificationId   string    `json:"identificationId"`
-	ValidForEnd        time.Time `json:"validFor.endDateTime"`
+	IdentificationType string     `json:"identificationType"`
+	IdentificationId   string     `json:"identificationId"`
+	ValidFor           TimePeriod `json:"validFor" gorm:"embedded;embeddedPrefix:valid_for_"`
 }
 
 type PartyCharacteristic struct {
 	gorm.Model
 	ID           string `json:"id" gorm:"primaryKey"`
+	TenantID     string `json:"-" gorm:"primaryKey"`
 	IndividualID string
 	Name         string `json:"name"`
 	Value        string `json:"value"`
 	ValueType    string `json:"valueType"`
 	Type         string `json:"@type"`
 }
+
+// TimePeriod is the TMF validFor / existsDuring structure.
+type TimePeriod struct {
+	StartDateTime *time.Time `json:"startDateTime,omitempty"`
+	EndDateTime   *time.Time `json:"endDateTime,omitempty"`
+}
+
+// OrganizationRef points at another Organization from a relationship.
+type OrganizationRef struct {
+	ID   string `json:"id"`
+	HREF string `json:"href,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Organization struct {
+	gorm.Model
+	ID               string     `json:"id" gorm:"primaryKey"`
+	TenantID         string     `json:"-" gorm:"primaryKey"`
+	HREF             string     `json:"href,omitempty"`
+	Name             string     `json:"name" validate:"required"`
+	NameType         string     `json:"nameType,omitempty"`
+	TradingName      string     `json:"tradingName,omitempty"`
+	OrganizationType string     `json:"organizationType,omitempty"`
+	IsLegalEntity    bool       `json:"isLegalEntity"`
+	IsHeadOffice     bool       `json:"isHeadOffice"`
+	Status           string     `json:"status,omitempty"`
+	ExistsDuring     TimePeriod `json:"existsDuring" gorm:"embedded;embeddedPrefix:exists_during_"`
+	CreationDate     time.Time  `json:"creationDate"`
+	ModificationDate time.Time  `json:"modificationDate"`
+	CreatedBy        string     `json:"createdBy"`
+	ModifiedBy       string     `json:"modifiedBy"`
+
+	ContactMedium                  []OrganizationContactMedium      `json:"contactMedium,omitempty" gorm:"foreignKey:OrganizationID" validate:"dive"`
+	ExternalReference              []OrganizationExternalReference  `json:"externalReference,omitempty" gorm:"foreignKey:OrganizationID" validate:"dive"`
+	OrganizationIdentification     []OrganizationIdentification     `json:"organizationIdentification,omitempty" gorm:"foreignKey:OrganizationID" validate:"dive"`
+	OrganizationChildRelationship  []OrganizationChildRelationship  `json:"organizationChildRelationship,omitempty" gorm:"foreignKey:OrganizationID" validate:"dive"`
+	OrganizationParentRelationship []OrganizationParentRelationship `json:"organizationParentRelationship,omitempty" gorm:"foreignKey:OrganizationID" validate:"dive"`
+}
+
+type OrganizationContactMedium struct {
+	gorm.Model
+	ID             string `json:"id" gorm:"primaryKey"`
+	TenantID       string `json:"-" gorm:"primaryKey"`
+	OrganizationID string
+	Type           string `json:"@type"`
+	MediumType     string `json:"mediumType" validate:"required,mediumtype"`
+	Preferred      bool   `json:"preferred"`
+
+	// For PhoneContactMedium
+	PhoneNumber string `json:"phoneNumber,omitempty" validate:"omitempty,e164"`
+
+	// For EmailContactMedium
+	EmailAddress string `json:"emailAddress,omitempty" validate:"omitempty,email"`
+
+	// For GeographicAddressContactMedium
+	Street1         string `json:"street1,omitempty"`
+	Street2         string `json:"street2,omitempty"`
+	City            string `json:"city,omitempty"`
+	StateOrProvince string `json:"stateOrProvince,omitempty"`
+	Country         string `json:"country,omitempty"`
+	PostCode        string `json:"postCode,omitempty"`
+}
+
+type OrganizationExternalReference struct {
+	gorm.Model
+	ID                     string `json:"id" gorm:"primaryKey"`
+	TenantID               string `json:"-" gorm:"primaryKey"`
+	OrganizationID         string
+	Name                   string `json:"name"`
+	ExternalIdentifierType string `json:"externalIdentifierType"`
+	Type                   string `json:"@type"`
+}
+
+type OrganizationIdentification struct {
+	gorm.Model
+	ID                 string `json:"id" gorm:"primaryKey"`
+	TenantID           string `json:"-" gorm:"primaryKey"`
+	OrganizationID     string
+	IdentificationType string     `json:"identificationType"`
+	IdentificationId   string     `json:"identificationId"`
+	IssuingAuthority   string     `json:"issuingAuthority,omitempty"`
+	IssuingDate        *time.Time `json:"issuingDate,omitempty"`
+	ValidFor           TimePeriod `json:"validFor" gorm:"embedded;embeddedPrefix:valid_for_"`
+}
+
+type OrganizationChildRelationship struct {
+	gorm.Model
+	ID               string `json:"id" gorm:"primaryKey"`
+	TenantID         string `json:"-" gorm:"primaryKey"`
+	OrganizationID   string
+	RelationshipType string          `json:"relationshipType,omitempty"`
+	Organization     OrganizationRef `json:"organization" gorm:"embedded;embeddedPrefix:child_"`
+}
+
+type OrganizationParentRelationship struct {
+	gorm.Model
+	ID               string `json:"id" gorm:"primaryKey"`
+	TenantID         string `json:"-" gorm:"primaryKey"`
+	OrganizationID   string
+	RelationshipType string          `json:"relationshipType,omitempty"`
+	Organization     OrganizationRef `json:"organization" gorm:"embedded;embeddedPrefix:parent_"`
+}
+
+// EventSubscription is a TMF630 hub registration: events matching Query are
+// POSTed to Callback. Roles, space separated, are those of the caller who
+// registered it, and decide which attributes the events reveal. Owner is
+// that caller's subject; only they may unregister it.
+type EventSubscription struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	TenantID     string    `json:"-" gorm:"primaryKey"`
+	Callback     string    `json:"callback" gorm:"not null"`
+	Query        string    `json:"query,omitempty"`
+	Roles        string    `json:"-"`
+	Owner        string    `json:"-"`
+	CreationDate time.Time `json:"-"`
+}
+
+// Outbox delivery states.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// OutboxEvent is an event recorded in the same transaction as the party
+// change that caused it, and delivered later by the outbox dispatcher.
+type OutboxEvent struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	EventID       string `gorm:"uniqueIndex;not null"`
+	EventType     string `gorm:"not null"`
+	Payload       string `gorm:"type:text;not null"`
+	Status        string `gorm:"index:idx_outbox_events_due,priority:1;not null"`
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time `gorm:"index:idx_outbox_events_due,priority:2"`
+	CreatedAt     time.Time
+	SentAt        *time.Time
+	// TraceParent is the W3C traceparent of the change that caused the
+	// event, so its delivery continues the same trace.
+	TraceParent string
+	// TenantID is the tenant of the change; only its subscribers are
+	// notified.
+	TenantID string
+	// Delivered lists, separated by spaces, the subscriptions the event was
+	// delivered to or failed permanently for; retries skip them.
+	Delivered string
+}
+
+// IdempotencyKey records the first response to a request sent with an
+// Idempotency-Key header, so a retry can be answered without repeating the
+// change. Keys are scoped to the tenant and operation they were sent to.
+type IdempotencyKey struct {
+	TenantID    string `gorm:"primaryKey"`
+	Scope       string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey"`
+	RequestHash string `gorm:"not null"`
+	StatusCode  int
+	Headers     string `gorm:"type:text"`
+	Body        string `gorm:"type:text"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index;not null"`
+}
+
+// Audited operations on a party.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditPatch  = "patch"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one change of a party: who made it, when, in which
+// request, and the party with its sub-resources before and after. Entries
+// are only ever appended. Each one names the Hash of the previous entry for
+// the same party, so altering or removing an entry breaks the chain.
+type AuditEntry struct {
+	ID           uint   `json:"-" gorm:"primaryKey;autoIncrement"`
+	TenantID     string `json:"-"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   string `json:"resourceId"`
+	// Sequence numbers the entries of a party from 1.
+	Sequence   int64     `json:"sequence"`
+	Operation  string    `json:"operation"`
+	Actor      string    `json:"actor,omitempty"`
+	RequestID  string    `json:"requestId,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+	// StateBefore and StateAfter hold the JSON form of the party, empty
+	// before its creation and after its deletion.
+	StateBefore  string `json:"-" gorm:"type:text"`
+	StateAfter   string `json:"-" gorm:"type:text"`
+	PreviousHash string `json:"previousHash"`
+	Hash         string `json:"hash"`
+}
@@ -0,0 +1,175 @@
+// internal/patch/patch.go
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Content types accepted on PATCH requests.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedContentType is returned for a patch body that is neither
+	// a JSON Merge Patch nor a JSON Patch.
+	ErrUnsupportedContentType = errors.New("unsupported patch content type")
+	// ErrInvalidPatch is returned when the patch document is malformed.
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrNotApplicable is returned when a well formed patch cannot be
+	// applied to the current resource, e.g. a failed "test" operation.
+	ErrNotApplicable = errors.New("patch cannot be applied")
+	// ErrReadOnly is returned when a patch modifies a read-only attribute.
+	ErrReadOnly = errors.New("read-only attribute")
+)
+
+// Apply patches doc according to contentType. Plain application/json is
+// treated as a merge patch, the TMF default.
+func Apply(contentType string, doc, body []byte) ([]byte, error) {
+	switch mediaType(contentType) {
+	case MergePatchContentType, "application/json":
+		if !json.Valid(body) || !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+		}
+		patched, err := jsonpatch.MergePatch(doc, body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotApplicable, err)
+		}
+		return patched, nil
+
+	case JSONPatchContentType:
+		ops, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		patched, err := ops.Apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotApplicable, err)
+		}
+		return patched, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+// Paths returns the attributes a patch reads or changes, as dotted paths
+// without array indices such as contactMedium.phoneNumber. The whole
+// document is the empty path. Operations Apply would reject are skipped.
+func Paths(contentType string, body []byte) ([]string, error) {
+	switch mediaType(contentType) {
+	case MergePatchContentType, "application/json":
+		var patch map[string]interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+		}
+		return mergePaths(nil, "", patch), nil
+
+	case JSONPatchContentType:
+		ops, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		var paths []string
+		for _, op := range ops {
+			if path, err := op.Path(); err == nil {
+				paths = append(paths, pointerPath(path))
+			}
+			if kind := op.Kind(); kind == "move" || kind == "copy" {
+				if from, err := op.From(); err == nil {
+					paths = append(paths, pointerPath(from))
+				}
+			}
+		}
+		return paths, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// mergePaths appends the paths of the members a merge patch sets or
+// removes. Nested objects are merged member by member; anything else
+// replaces the attribute whole.
+func mergePaths(paths []string, prefix string, patch map[string]interface{}) []string {
+	for name, value := range patch {
+		path := prefix + name
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			paths = mergePaths(paths, path+".", nested)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// pointerPath turns a JSON pointer such as /contactMedium/0/phoneNumber
+// into the dotted path contactMedium.phoneNumber.
+func pointerPath(pointer string) string {
+	var names []string
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		if token == "-" || isIndex(token) {
+			continue
+		}
+		names = append(names, strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+	}
+	return strings.Join(names, ".")
+}
+
+func isIndex(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckReadOnly returns ErrReadOnly if any of the top-level attributes in
+// readOnly differ between the before and after documents.
+func CheckReadOnly(before, after []byte, readOnly []string) error {
+	var b, a map[string]json.RawMessage
+	if err := json.Unmarshal(before, &b); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(after, &a); err != nil {
+		return fmt.Errorf("%w: patched document must be a JSON object", ErrNotApplicable)
+	}
+
+	for _, name := range readOnly {
+		if !jsonEqual(b[name], a[name]) {
+			return fmt.Errorf("%w: %s", ErrReadOnly, name)
+		}
+	}
+	return nil
+}
+
+func jsonEqual(x, y json.RawMessage) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	var vx, vy interface{}
+	if json.Unmarshal(x, &vx) != nil || json.Unmarshal(y, &vy) != nil {
+		return bytes.Equal(x, y)
+	}
+	cx, _ := json.Marshal(vx)
+	cy, _ := json.Marshal(vy)
+	return bytes.Equal(cx, cy)
+}
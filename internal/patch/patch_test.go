@@ -0,0 +1,120 @@
+// internal/patch/patch_test.go
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const doc = `{"id":"i1","givenName":"Ann","title":"Dr","contactMedium":[{"id":"c1","mediumType":"email"},{"id":"c2","mediumType":"phone"}]}`
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		err         error
+	}{
+		{
+			"merge patch", MergePatchContentType,
+			`{"givenName":"Anne","title":null}`,
+			`{"id":"i1","givenName":"Anne","contactMedium":[{"id":"c1","mediumType":"email"},{"id":"c2","mediumType":"phone"}]}`, nil,
+		},
+		{
+			"merge patch replaces arrays", "application/merge-patch+json; charset=utf-8",
+			`{"contactMedium":[{"id":"c2","mediumType":"mobile"}]}`,
+			`{"id":"i1","givenName":"Ann","title":"Dr","contactMedium":[{"id":"c2","mediumType":"mobile"}]}`, nil,
+		},
+		{
+			"plain JSON is a merge patch", "application/json",
+			`{"title":"Prof"}`,
+			`{"id":"i1","givenName":"Ann","title":"Prof","contactMedium":[{"id":"c1","mediumType":"email"},{"id":"c2","mediumType":"phone"}]}`, nil,
+		},
+		{
+			"JSON patch", JSONPatchContentType,
+			`[{"op":"replace","path":"/contactMedium/1/mediumType","value":"mobile"},{"op":"remove","path":"/title"}]`,
+			`{"id":"i1","givenName":"Ann","contactMedium":[{"id":"c1","mediumType":"email"},{"id":"c2","mediumType":"mobile"}]}`, nil,
+		},
+		{"merge patch that is no object", MergePatchContentType, `["x"]`, "", ErrInvalidPatch},
+		{"malformed JSON patch", JSONPatchContentType, `{"op":"remove"}`, "", ErrInvalidPatch},
+		{"failed test", JSONPatchContentType, `[{"op":"test","path":"/givenName","value":"Bob"}]`, "", ErrNotApplicable},
+		{"missing path", JSONPatchContentType, `[{"op":"remove","path":"/nickname"}]`, "", ErrNotApplicable},
+		{"unsupported content type", "text/plain", `x`, "", ErrUnsupportedContentType},
+	}
+	for _, tt := range tests {
+		got, err := Apply(tt.contentType, []byte(doc), []byte(tt.body))
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !jsonEqual(got, []byte(tt.want)) {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	readOnly := []string{"id", "href"}
+	tests := []struct {
+		after string
+		err   error
+	}{
+		{`{"id":"i1","givenName":"Anne"}`, nil},
+		// Formatting does not count as a change
+		{`{ "givenName" : "Anne", "id" : "i1" }`, nil},
+		{`{"id":"i2","givenName":"Ann"}`, ErrReadOnly},
+		{`{"givenName":"Ann"}`, ErrReadOnly},
+		{`{"id":"i1","href":"/elsewhere"}`, ErrReadOnly},
+		{`["i1"]`, ErrNotApplicable},
+	}
+	for _, tt := range tests {
+		err := CheckReadOnly([]byte(`{"id":"i1","givenName":"Ann"}`), []byte(tt.after), readOnly)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("%s: got %v, want %v", tt.after, err, tt.err)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        []string
+	}{
+		{MergePatchContentType, `{"givenName":"Anne","title":null}`, []string{"givenName", "title"}},
+		{MergePatchContentType, `{"validFor":{"endDateTime":"2030-01-01T00:00:00Z"}}`, []string{"validFor.endDateTime"}},
+		{MergePatchContentType, `{"contactMedium":[]}`, []string{"contactMedium"}},
+		{JSONPatchContentType, `[{"op":"test","path":"/contactMedium/0/phoneNumber","value":"1"}]`, []string{"contactMedium.phoneNumber"}},
+		{JSONPatchContentType, `[{"op":"add","path":"/contactMedium/-","value":{}}]`, []string{"contactMedium"}},
+		{JSONPatchContentType, `[{"op":"copy","from":"/familyName","path":"/givenName"}]`, []string{"familyName", "givenName"}},
+		{JSONPatchContentType, `[{"op":"replace","path":"/a~1b/c~0d","value":1}]`, []string{"a/b.c~d"}},
+		{JSONPatchContentType, `[{"op":"replace","path":"","value":{}}]`, []string{""}},
+	}
+	for _, tt := range tests {
+		got, err := Paths(tt.contentType, []byte(tt.body))
+		if err != nil {
+			t.Errorf("%s: %v", tt.body, err)
+			continue
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.body, got, tt.want)
+		}
+	}
+
+	if _, err := Paths(MergePatchContentType, []byte(`[]`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("merge patch array: got %v, want ErrInvalidPatch", err)
+	}
+	if _, err := Paths("text/plain", []byte(`x`)); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("text/plain: got %v, want ErrUnsupportedContentType", err)
+	}
+}
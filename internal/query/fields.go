@@ -0,0 +1,182 @@
+// internal/query/fields.go
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidFields is returned when ?fields= names an unknown attribute.
+var ErrInvalidFields = errors.New("invalid fields selection")
+
+// alwaysIncluded are returned whatever the client selects, as TMF630
+// requires a resource to stay identifiable.
+var alwaysIncluded = []string{"id", "href", "@type", "@baseType", "@schemaLocation"}
+
+// Fields is the TMF630 attribute selection requested with ?fields=. The
+// zero value selects every attribute.
+type Fields struct {
+	names map[string]bool
+}
+
+// ParseFields reads the comma separated fields parameter. Nested paths such
+// as contactMedium.mediumType select the whole top-level attribute.
+func ParseFields(params url.Values) Fields {
+	raw := params.Get("fields")
+	if raw == "" {
+		return Fields{}
+	}
+
+	f := Fields{names: map[string]bool{}}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if i := strings.Index(name, "."); i > 0 {
+			name = name[:i]
+		}
+		if name != "" {
+			f.names[name] = true
+		}
+	}
+	for _, name := range alwaysIncluded {
+		f.names[name] = true
+	}
+	return f
+}
+
+// All reports whether every attribute is selected.
+func (f Fields) All() bool {
+	return f.names == nil
+}
+
+// Includes reports whether the top-level attribute name is selected.
+func (f Fields) Includes(name string) bool {
+	return f.All() || f.names[name]
+}
+
+// Apply narrows db to the columns backing the selected attributes and
+// preloads only the selected sub-resources. With no selection every
+// sub-resource is preloaded when preloadAll is set, otherwise none are.
+func (f Fields) Apply(db *gorm.DB, model interface{}, preloadAll bool) (*gorm.DB, error) {
+	sch, err := parseSchema(db, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.validate(sch); err != nil {
+		return nil, err
+	}
+
+	var preloads []string
+	for _, rel := range sch.Relationships.Relations {
+		name, ok := JSONPath(sch.ModelType, []string{rel.Name})
+		if !ok {
+			continue
+		}
+		if (f.All() && preloadAll) || (!f.All() && f.names[name]) {
+			preloads = append(preloads, rel.Name)
+		}
+	}
+
+	var columns []string
+	for _, field := range sch.Fields {
+		path, ok := JSONPath(sch.ModelType, field.BindNames)
+		if !ok || field.DBName == "" {
+			continue
+		}
+		top := strings.SplitN(path, ".", 2)[0]
+		// Sub-resources are joined back on the primary key, so it is
+		// selected even when the client did not ask for it.
+		if f.Includes(top) || field.PrimaryKey {
+			columns = append(columns, field.DBName)
+		}
+	}
+
+	for _, name := range preloads {
+		db = db.Preload(name)
+	}
+	if !f.All() {
+		db = db.Select(columns)
+	}
+	return db, nil
+}
+
+// Validate reports ErrInvalidFields when the selection names an attribute
+// model does not have.
+func (f Fields) Validate(model interface{}) error {
+	sch, err := ParseSchema(model)
+	if err != nil {
+		return err
+	}
+	return f.validate(sch)
+}
+
+func (f Fields) validate(sch *schema.Schema) error {
+	known := map[string]bool{}
+	for _, name := range alwaysIncluded {
+		known[name] = true
+	}
+	for _, rel := range sch.Relationships.Relations {
+		if name, ok := JSONPath(sch.ModelType, []string{rel.Name}); ok {
+			known[name] = true
+		}
+	}
+	for _, field := range sch.Fields {
+		if path, ok := JSONPath(sch.ModelType, field.BindNames); ok && field.DBName != "" {
+			known[strings.SplitN(path, ".", 2)[0]] = true
+		}
+	}
+
+	for name := range f.names {
+		if !known[name] {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidFields, name)
+		}
+	}
+	return nil
+}
+
+// Project prunes the JSON form of v, a resource or a slice of resources, to
+// the selected attributes.
+func (f Fields) Project(v interface{}) (interface{}, error) {
+	if f.All() {
+		return v, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(raw) == "null" {
+		return v, nil
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			f.prune(item)
+		}
+		return items, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+	f.prune(item)
+	return item, nil
+}
+
+func (f Fields) prune(item map[string]json.RawMessage) {
+	for key := range item {
+		if !f.names[key] {
+			delete(item, key)
+		}
+	}
+}
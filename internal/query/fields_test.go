@@ -0,0 +1,112 @@
+// internal/query/fields_test.go
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/models"
+)
+
+func TestParseFields(t *testing.T) {
+	if f := ParseFields(url.Values{}); !f.All() {
+		t.Error("no fields parameter must select everything")
+	}
+
+	f := ParseFields(url.Values{"fields": {"givenName, contactMedium.mediumType,,"}})
+	if f.All() {
+		t.Fatal("a selection must not select everything")
+	}
+	for _, name := range []string{"givenName", "contactMedium", "id", "href", "@type"} {
+		if !f.Includes(name) {
+			t.Errorf("%s is not included", name)
+		}
+	}
+	for _, name := range []string{"familyName", "contactMedium.mediumType", ""} {
+		if f.Includes(name) {
+			t.Errorf("%s is included", name)
+		}
+	}
+}
+
+func TestFieldsValidate(t *testing.T) {
+	tests := []struct {
+		fields string
+		model  interface{}
+		ok     bool
+	}{
+		{"givenName,contactMedium", &models.Individual{}, true},
+		{"individualIdentification.validFor", &models.Individual{}, true},
+		{"existsDuring", &models.Organization{}, true},
+		{"bogus", &models.Individual{}, false},
+		{"givenName", &models.Organization{}, false},
+		// Storage attributes have no JSON path
+		{"ID", &models.Individual{}, false},
+	}
+	for _, tt := range tests {
+		err := ParseFields(url.Values{"fields": {tt.fields}}).Validate(tt.model)
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.fields, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidFields) {
+			t.Errorf("%s: got %v, want ErrInvalidFields", tt.fields, err)
+		}
+	}
+}
+
+func TestFieldsProject(t *testing.T) {
+	ann := models.Individual{
+		ID: "i1", HREF: "/individual/i1", GivenName: "Ann", FamilyName: "Smith",
+		ContactMedium: []models.ContactMedium{{ID: "c1", MediumType: "email"}},
+	}
+	f := ParseFields(url.Values{"fields": {"givenName"}})
+
+	projected, err := f.Project(ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(t, projected), []string{"givenName", "href", "id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resource: got %v, want %v", got, want)
+	}
+
+	projected, err = f.Project([]models.Individual{ann, ann})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []map[string]interface{}
+	data, _ := json.Marshal(projected)
+	if err := json.Unmarshal(data, &items); err != nil || len(items) != 2 {
+		t.Fatalf("list: %s", data)
+	}
+	for _, item := range items {
+		if len(item) != 3 || item["givenName"] != "Ann" {
+			t.Errorf("list item: %v", item)
+		}
+	}
+
+	if same, _ := (Fields{}).Project(ann); !reflect.DeepEqual(same, ann) {
+		t.Error("no selection must return the resource unchanged")
+	}
+}
+
+func keys(t *testing.T, v interface{}) []string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
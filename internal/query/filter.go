@@ -0,0 +1,300 @@
+// internal/query/filter.go
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidFilter is returned when a query parameter cannot be turned into
+// a filter on the target resource.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Operators that may be appended to an attribute name, as in
+// ?creationDate.gt=2023-01-01 or ?gender.in=M,F.
+const (
+	OpEq    = "eq"
+	OpNe    = "ne"
+	OpGt    = "gt"
+	OpGte   = "gte"
+	OpLt    = "lt"
+	OpLte   = "lte"
+	OpRegex = "regex"
+	OpIn    = "in"
+)
+
+var operators = map[string]bool{
+	OpEq:    true,
+	OpNe:    true,
+	OpGt:    true,
+	OpGte:   true,
+	OpLt:    true,
+	OpLte:   true,
+	OpRegex: true,
+	OpIn:    true,
+}
+
+// ReservedParams are TMF630 query parameters that control the response
+// rather than select resources, so they are never treated as filters.
+var ReservedParams = map[string]bool{
+	"fields": true,
+	"limit":  true,
+	"offset": true,
+	"sort":   true,
+}
+
+// Filter is a single attribute condition parsed from the query string.
+type Filter struct {
+	// Path is the JSON attribute path, e.g. "familyName" or
+	// "contactMedium.mediumType".
+	Path     string
+	Operator string
+	Values   []string
+}
+
+// ParseFilters extracts attribute filters from TMF630 query parameters.
+// Filters are returned in a stable order so generated SQL is deterministic.
+func ParseFilters(params url.Values) []Filter {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var filters []Filter
+	for _, key := range keys {
+		if ReservedParams[key] {
+			continue
+		}
+
+		path, op := key, OpEq
+		if i := strings.LastIndex(key, "."); i > 0 && operators[key[i+1:]] {
+			path, op = key[:i], key[i+1:]
+		}
+
+		for _, raw := range params[key] {
+			values := []string{raw}
+			// TMF630: a comma separated list on equality means "any of".
+			if op == OpIn || op == OpEq {
+				values = strings.Split(raw, ",")
+			}
+			filters = append(filters, Filter{Path: path, Operator: op, Values: values})
+		}
+	}
+	return filters
+}
+
+// ApplyFilters applies filters to db as parameterised conditions on model.
+// Unknown attributes and malformed values are reported as ErrInvalidFilter.
+func ApplyFilters(db *gorm.DB, model interface{}, filters []Filter) (*gorm.DB, error) {
+	if len(filters) == 0 {
+		return db, nil
+	}
+
+	sch, err := parseSchema(db, model)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := attributeFields(sch)
+	for _, f := range filters {
+		if field, ok := fields[f.Path]; ok {
+			cond, err := condition(db, field, f)
+			if err != nil {
+				return nil, err
+			}
+			db = db.Where(cond)
+			continue
+		}
+
+		rel, subPath, ok := relationshipFor(sch, f.Path)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidFilter, f.Path)
+		}
+		field, ok := attributeFields(rel.FieldSchema)[subPath]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidFilter, f.Path)
+		}
+		cond, err := condition(db, field, f)
+		if err != nil {
+			return nil, err
+		}
+
+		// Sub-resource filters become "id IN (SELECT owner_id FROM child
+		// WHERE ...)" so the parent rows are not duplicated by a join.
+		if len(rel.References) == 0 {
+			return nil, fmt.Errorf("%w: %q cannot be filtered", ErrInvalidFilter, f.Path)
+		}
+		ref := rel.References[0]
+		sub := db.Session(&gorm.Session{NewDB: true}).
+			Model(reflect.New(rel.FieldSchema.ModelType).Interface()).
+			Select(ref.ForeignKey.DBName).
+			Where(cond)
+		db = db.Where(clause.Expr{
+			SQL:  "? IN (?)",
+			Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: ref.PrimaryKey.DBName}, sub},
+		})
+	}
+
+	return db, nil
+}
+
+func parseSchema(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+var schemaCache sync.Map
+
+// ParseSchema returns the GORM schema of model under the default naming
+// strategy, for callers that have no database connection.
+func ParseSchema(model interface{}) (*schema.Schema, error) {
+	return schema.Parse(model, &schemaCache, schema.NamingStrategy{})
+}
+
+// attributeFields indexes the persisted fields of sch by their JSON path.
+// Fields without a json tag (such as those from gorm.Model) are not
+// addressable by clients.
+func attributeFields(sch *schema.Schema) map[string]*schema.Field {
+	fields := make(map[string]*schema.Field, len(sch.Fields))
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if path, ok := JSONPath(sch.ModelType, field.BindNames); ok {
+			fields[path] = field
+		}
+	}
+	return fields
+}
+
+// JSONPath translates a chain of Go field names into the dotted JSON path
+// clients use. Anonymous embedded structs contribute no path segment.
+func JSONPath(t reflect.Type, bindNames []string) (string, bool) {
+	var segments []string
+	for _, name := range bindNames {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok {
+			return "", false
+		}
+		t = sf.Type
+		if sf.Anonymous {
+			continue
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			return "", false
+		}
+		segments = append(segments, tag)
+	}
+	return strings.Join(segments, "."), len(segments) > 0
+}
+
+// relationshipFor finds the sub-resource addressed by the first segment of
+// path and returns the remainder of the path within it.
+func relationshipFor(sch *schema.Schema, path string) (*schema.Relationship, string, bool) {
+	for _, rel := range sch.Relationships.Relations {
+		name, ok := JSONPath(sch.ModelType, []string{rel.Name})
+		if !ok || !strings.HasPrefix(path, name+".") {
+			continue
+		}
+		return rel, strings.TrimPrefix(path, name+"."), true
+	}
+	return nil, "", false
+}
+
+func condition(db *gorm.DB, field *schema.Field, f Filter) (clause.Expression, error) {
+	column := clause.Column{Name: field.DBName}
+
+	values := make([]interface{}, 0, len(f.Values))
+	for _, raw := range f.Values {
+		if f.Operator == OpRegex {
+			values = append(values, raw)
+			continue
+		}
+		v, err := convert(field, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, f.Path, err)
+		}
+		values = append(values, v)
+	}
+
+	switch f.Operator {
+	case OpEq, OpIn:
+		if len(values) == 1 {
+			return clause.Eq{Column: column, Value: values[0]}, nil
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case OpNe:
+		return clause.Neq{Column: column, Value: values[0]}, nil
+	case OpGt:
+		return clause.Gt{Column: column, Value: values[0]}, nil
+	case OpGte:
+		return clause.Gte{Column: column, Value: values[0]}, nil
+	case OpLt:
+		return clause.Lt{Column: column, Value: values[0]}, nil
+	case OpLte:
+		return clause.Lte{Column: column, Value: values[0]}, nil
+	case OpRegex:
+		return clause.Expr{SQL: regexSQL(db), Vars: []interface{}{column, values[0]}}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidFilter, f.Operator)
+}
+
+func regexSQL(db *gorm.DB) string {
+	if db.Dialector != nil && db.Dialector.Name() == "postgres" {
+		return "? ~ ?"
+	}
+	return "? REGEXP ?"
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// convert parses raw into the Go type of field so comparisons are typed
+// rather than textual.
+func convert(field *schema.Field, raw string) (interface{}, error) {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			if v, err := time.Parse(layout, raw); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a date-time", raw)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	case reflect.String:
+		return raw, nil
+	}
+	return nil, fmt.Errorf("attribute type %s cannot be filtered", t)
+}
@@ -0,0 +1,98 @@
+// internal/query/filter_test.go
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Filter
+	}{
+		{"familyName=Smith", []Filter{{Path: "familyName", Operator: OpEq, Values: []string{"Smith"}}}},
+		{"familyName=Smith,Jones", []Filter{{Path: "familyName", Operator: OpEq, Values: []string{"Smith", "Jones"}}}},
+		{"creationDate.gt=2023-01-01", []Filter{{Path: "creationDate", Operator: OpGt, Values: []string{"2023-01-01"}}}},
+		{"givenName.regex=^J,o", []Filter{{Path: "givenName", Operator: OpRegex, Values: []string{"^J,o"}}}},
+		{"contactMedium.mediumType.in=email,phone", []Filter{{Path: "contactMedium.mediumType", Operator: OpIn, Values: []string{"email", "phone"}}}},
+		// A segment that is no operator is part of the path
+		{"contactMedium.mediumType=email", []Filter{{Path: "contactMedium.mediumType", Operator: OpEq, Values: []string{"email"}}}},
+		{"fields=id&limit=1&offset=2&sort=id", nil},
+		{"b=2&a=1", []Filter{
+			{Path: "a", Operator: OpEq, Values: []string{"1"}},
+			{Path: "b", Operator: OpEq, Values: []string{"2"}},
+		}},
+	}
+	for _, tt := range tests {
+		params, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ParseFilters(params); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestApplyFilters(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query string
+		where string
+		vars  []interface{}
+	}{
+		{"familyName=Smith", `"family_name" = $1`, []interface{}{"Smith"}},
+		{"familyName=Smith,Jones", `"family_name" IN ($1,$2)`, []interface{}{"Smith", "Jones"}},
+		{"familyName.ne=Smith", `"family_name" <> $1`, []interface{}{"Smith"}},
+		{"givenName.regex=^J", `"given_name" ~ $1`, []interface{}{"^J"}},
+		{"creationDate.gt=2023-01-01", `"creation_date" > $1`, []interface{}{day}},
+		{"creationDate.lte=2023-01-01T00:00:00Z", `"creation_date" <= $1`, []interface{}{day}},
+		{
+			"contactMedium.mediumType=email",
+			`"individuals"."id" IN (SELECT "individual_id" FROM "contact_media" WHERE "medium_type" = $1 AND "contact_media"."deleted_at" IS NULL)`,
+			[]interface{}{"email"},
+		},
+	}
+	for _, tt := range tests {
+		params, _ := url.ParseQuery(tt.query)
+		filtered, err := ApplyFilters(db.Model(&models.Individual{}), &models.Individual{}, ParseFilters(params))
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		var found []models.Individual
+		stmt := filtered.Find(&found).Statement
+		want := `SELECT * FROM "individuals" WHERE ` + tt.where + ` AND "individuals"."deleted_at" IS NULL`
+		if got := stmt.SQL.String(); got != want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.query, got, want)
+		}
+		if !reflect.DeepEqual(stmt.Vars, tt.vars) {
+			t.Errorf("%s: got vars %v, want %v", tt.query, stmt.Vars, tt.vars)
+		}
+	}
+
+	for _, q := range []string{
+		"bogus=1",
+		"creationDate.gt=yesterday",
+		"contactMedium.bogus=email",
+		"ID=1",
+	} {
+		params, _ := url.ParseQuery(q)
+		if _, err := ApplyFilters(db.Model(&models.Individual{}), &models.Individual{}, ParseFilters(params)); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: got %v, want ErrInvalidFilter", q, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// internal/query/match.go
+package query
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Matcher evaluates attribute filters against resources held in memory,
+// with the semantics ApplyFilters gives them in SQL: a nil attribute
+// matches nothing, and a sub-resource filter holds when any entry matches.
+type Matcher struct {
+	conditions []memoryCondition
+}
+
+type memoryCondition struct {
+	// rel is the sub-resource the field belongs to, or nil for an
+	// attribute of the resource itself.
+	rel      *schema.Relationship
+	field    *schema.Field
+	operator string
+	values   []interface{}
+	regex    *regexp.Regexp
+}
+
+// NewMatcher compiles filters on model. Unknown attributes and malformed
+// values are reported as ErrInvalidFilter, as ApplyFilters does.
+func NewMatcher(model interface{}, filters []Filter) (*Matcher, error) {
+	sch, err := ParseSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{}
+	fields := attributeFields(sch)
+	for _, f := range filters {
+		cond := memoryCondition{operator: f.Operator}
+
+		if field, ok := fields[f.Path]; ok {
+			cond.field = field
+		} else {
+			rel, subPath, ok := relationshipFor(sch, f.Path)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidFilter, f.Path)
+			}
+			field, ok := attributeFields(rel.FieldSchema)[subPath]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidFilter, f.Path)
+			}
+			if len(rel.References) == 0 {
+				return nil, fmt.Errorf("%w: %q cannot be filtered", ErrInvalidFilter, f.Path)
+			}
+			cond.rel, cond.field = rel, field
+		}
+
+		if f.Operator == OpRegex {
+			re, err := regexp.Compile(f.Values[0])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, f.Path, err)
+			}
+			cond.regex = re
+		} else {
+			for _, raw := range f.Values {
+				v, err := convert(cond.field, raw)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, f.Path, err)
+				}
+				cond.values = append(cond.values, v)
+			}
+		}
+		m.conditions = append(m.conditions, cond)
+	}
+	return m, nil
+}
+
+// Match reports whether resource, a pointer to or value of the model the
+// matcher was compiled for, satisfies every filter.
+func (m *Matcher) Match(resource interface{}) bool {
+	value := reflect.Indirect(reflect.ValueOf(resource))
+	for _, cond := range m.conditions {
+		if !cond.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c memoryCondition) matches(value reflect.Value) bool {
+	if c.rel == nil {
+		return c.test(value)
+	}
+	children := c.rel.Field.ReflectValueOf(context.Background(), value)
+	for i := 0; i < children.Len(); i++ {
+		if c.test(children.Index(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c memoryCondition) test(value reflect.Value) bool {
+	raw, _ := c.field.ValueOf(context.Background(), value)
+	actual, ok := normalize(raw)
+	if !ok {
+		return false
+	}
+
+	if c.operator == OpRegex {
+		return c.regex.MatchString(fmt.Sprint(actual))
+	}
+
+	switch c.operator {
+	case OpEq, OpIn:
+		for _, v := range c.values {
+			if cmp, ok := compare(actual, v); ok && cmp == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	cmp, ok := compare(actual, c.values[0])
+	if !ok {
+		return false
+	}
+	switch c.operator {
+	case OpNe:
+		return cmp != 0
+	case OpGt:
+		return cmp > 0
+	case OpGte:
+		return cmp >= 0
+	case OpLt:
+		return cmp < 0
+	case OpLte:
+		return cmp <= 0
+	}
+	return false
+}
+
+// normalize converts a field value to the types convert produces. It
+// reports false for a nil pointer, which SQL sees as NULL.
+func normalize(raw interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(raw)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Type() == timeType {
+		return v.Interface(), true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return v.String(), true
+	}
+	return v.Interface(), true
+}
+
+// compare orders a and b, which must be of the same normalized type.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		return strings.Compare(a, b), ok
+	case int64:
+		b, ok := b.(int64)
+		return ordered(a < b, a > b), ok
+	case uint64:
+		b, ok := b.(uint64)
+		return ordered(a < b, a > b), ok
+	case float64:
+		b, ok := b.(float64)
+		return ordered(a < b, a > b), ok
+	case bool:
+		b, ok := b.(bool)
+		return ordered(!a && b, a && !b), ok
+	case time.Time:
+		b, ok := b.(time.Time)
+		return a.Compare(b), ok
+	}
+	return 0, false
+}
+
+func ordered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
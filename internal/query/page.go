@@ -0,0 +1,96 @@
+// internal/query/page.go
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidPage is returned for a malformed offset or limit.
+var ErrInvalidPage = errors.New("invalid pagination")
+
+// Page is the TMF630 offset/limit window requested by a client.
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// ParsePage reads offset and limit from params. A missing limit falls back
+// to defaultLimit and any limit above maxLimit is capped to it.
+func ParsePage(params url.Values, defaultLimit, maxLimit int) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	if raw := params.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return Page{}, fmt.Errorf("%w: offset must be a non-negative integer", ErrInvalidPage)
+		}
+		page.Offset = n
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return Page{}, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidPage)
+		}
+		page.Limit = n
+	}
+	if page.Limit > maxLimit {
+		page.Limit = maxLimit
+	}
+
+	return page, nil
+}
+
+// Apply restricts db to the page, ordered by primary key so that successive
+// pages neither overlap nor skip rows.
+func (p Page) Apply(db *gorm.DB) *gorm.DB {
+	return db.
+		Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}}).
+		Offset(p.Offset).
+		Limit(p.Limit)
+}
+
+// WriteHeaders sets X-Total-Count, X-Result-Count and RFC 8288 next/prev
+// links on header and returns the status for the response: 206 Partial
+// Content when count is fewer than total, otherwise 200.
+func (p Page) WriteHeaders(header http.Header, requestURL *url.URL, total int64, count int) int {
+	header.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	header.Set("X-Result-Count", strconv.Itoa(count))
+
+	var links []string
+	if next := p.Offset + count; int64(next) < total && count > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(requestURL, next, p.Limit)))
+	}
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(requestURL, prev, p.Limit)))
+	}
+	if len(links) > 0 {
+		header.Set("Link", strings.Join(links, ", "))
+	}
+
+	if int64(count) < total {
+		return http.StatusPartialContent
+	}
+	return http.StatusOK
+}
+
+func pageURL(requestURL *url.URL, offset, limit int) string {
+	u := *requestURL
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
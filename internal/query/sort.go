@@ -0,0 +1,134 @@
+// internal/query/sort.go
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidSort is returned for a sort on an attribute that is unknown or
+// belongs to a sub-resource, which has no single value per resource.
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortKey is one attribute of a TMF630 sort.
+type SortKey struct {
+	Path       string
+	Descending bool
+}
+
+// Sort lists the attributes a list is ordered by, most significant first.
+// Resources equal on all of them remain ordered by id.
+type Sort []SortKey
+
+// ParseSort reads the comma separated attributes of the sort parameter. A
+// leading '-' sorts an attribute in descending order; a leading '+', or
+// none, in ascending order.
+func ParseSort(params url.Values) Sort {
+	var s Sort
+	for _, raw := range params["sort"] {
+		for _, path := range strings.Split(raw, ",") {
+			// An unencoded '+' arrives as a space
+			path = strings.TrimSpace(path)
+			key := SortKey{Path: strings.TrimPrefix(path, "+")}
+			if strings.HasPrefix(path, "-") {
+				key = SortKey{Path: path[1:], Descending: true}
+			}
+			if key.Path != "" {
+				s = append(s, key)
+			}
+		}
+	}
+	return s
+}
+
+// sortFields returns the field of sch each key sorts on.
+func (s Sort) sortFields(sch *schema.Schema) ([]*schema.Field, error) {
+	attributes := attributeFields(sch)
+	fields := make([]*schema.Field, len(s))
+	for i, key := range s {
+		field, ok := attributes[key.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not an attribute that can be sorted on", ErrInvalidSort, key.Path)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// Apply orders db by s. Page.Apply, applied after it, orders by id last so
+// that ties still page consistently.
+func (s Sort) Apply(db *gorm.DB, model interface{}) (*gorm.DB, error) {
+	if len(s) == 0 {
+		return db, nil
+	}
+	sch, err := parseSchema(db, model)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := s.sortFields(sch)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range s {
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: fields[i].DBName},
+			Desc:   key.Descending,
+		})
+	}
+	return db, nil
+}
+
+// Sorter orders resources held in memory as Sort.Apply orders rows. Nil
+// attributes, which SQL databases place differently, come first.
+type Sorter struct {
+	keys   Sort
+	fields []*schema.Field
+}
+
+// NewSorter compiles s for model, reporting ErrInvalidSort as Apply does.
+func NewSorter(model interface{}, s Sort) (*Sorter, error) {
+	sch, err := ParseSchema(model)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := s.sortFields(sch)
+	if err != nil {
+		return nil, err
+	}
+	return &Sorter{keys: s, fields: fields}, nil
+}
+
+// Compare orders a and b, pointers to or values of the model the sorter
+// was compiled for, returning 0 when they are equal on every attribute.
+func (s *Sorter) Compare(a, b interface{}) int {
+	va, vb := reflect.Indirect(reflect.ValueOf(a)), reflect.Indirect(reflect.ValueOf(b))
+	for i, field := range s.fields {
+		rawA, _ := field.ValueOf(context.Background(), va)
+		rawB, _ := field.ValueOf(context.Background(), vb)
+		x, okA := normalize(rawA)
+		y, okB := normalize(rawB)
+
+		var cmp int
+		switch {
+		case !okA || !okB:
+			cmp = ordered(!okA && okB, okA && !okB)
+		default:
+			cmp, _ = compare(x, y)
+		}
+		if s.keys[i].Descending {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
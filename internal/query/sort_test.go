@@ -0,0 +1,103 @@
+// internal/query/sort_test.go
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Sort
+	}{
+		{"", nil},
+		{"sort=familyName", Sort{{Path: "familyName"}}},
+		{"sort=-creationDate,%2BgivenName", Sort{{Path: "creationDate", Descending: true}, {Path: "givenName"}}},
+		// An unencoded '+' decodes to a space
+		{"sort=+givenName,,", Sort{{Path: "givenName"}}},
+		{"sort=a&sort=-b", Sort{{Path: "a"}, {Path: "b", Descending: true}}},
+	}
+	for _, tt := range tests {
+		params, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ParseSort(params); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSortApply(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sorted, err := Sort{{Path: "familyName", Descending: true}, {Path: "creationDate"}}.Apply(db.Model(&models.Individual{}), &models.Individual{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []models.Individual
+	stmt := Page{Limit: 5}.Apply(sorted).Find(&found).Statement
+	want := `SELECT * FROM "individuals" WHERE "individuals"."deleted_at" IS NULL ` +
+		`ORDER BY "individuals"."family_name" DESC,"individuals"."creation_date","individuals"."id" LIMIT 5`
+	if got := stmt.SQL.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	for _, path := range []string{"bogus", "contactMedium.mediumType", "ID"} {
+		if _, err := (Sort{{Path: path}}).Apply(db.Model(&models.Individual{}), &models.Individual{}); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("%s: got %v, want ErrInvalidSort", path, err)
+		}
+		if _, err := NewSorter(&models.Individual{}, Sort{{Path: path}}); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("sorter %s: got %v, want ErrInvalidSort", path, err)
+		}
+	}
+}
+
+func TestSorter(t *testing.T) {
+	day := func(d int) *time.Time {
+		v := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	organizations := []models.Organization{
+		{ID: "o1", Name: "b", ExistsDuring: models.TimePeriod{StartDateTime: day(2)}},
+		{ID: "o2", Name: "a", ExistsDuring: models.TimePeriod{StartDateTime: day(1)}},
+		{ID: "o3", Name: "b"},
+		{ID: "o4", Name: "a", ExistsDuring: models.TimePeriod{StartDateTime: day(3)}},
+	}
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"name", []string{"o2", "o4", "o1", "o3"}},
+		{"-name,existsDuring.startDateTime", []string{"o3", "o1", "o2", "o4"}},
+		// Nil attributes come first, and last when descending
+		{"-existsDuring.startDateTime", []string{"o4", "o1", "o2", "o3"}},
+	}
+	for _, tt := range tests {
+		sorter, err := NewSorter(&models.Organization{}, ParseSort(url.Values{"sort": {tt.sort}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorted := append([]models.Organization(nil), organizations...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorter.Compare(&sorted[i], &sorted[j]) < 0 })
+		var got []string
+		for _, o := range sorted {
+			got = append(got, o.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort=%s: got %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+}
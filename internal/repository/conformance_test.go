@@ -0,0 +1,578 @@
+// internal/repository/conformance_test.go
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/database"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/repository"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// postgresDSNEnv names a Postgres database the conformance tests may wipe.
+// Without it the Postgres backend is skipped.
+const postgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// backend opens an empty repository. db is nil for backends without SQL.
+type backend struct {
+	name string
+	open func(t *testing.T) (repository.Repository, *gorm.DB)
+}
+
+var backends = []backend{
+	{"memory", func(t *testing.T) (repository.Repository, *gorm.DB) {
+		return repository.NewMemory(nil), nil
+	}},
+	{"gorm/sqlite", func(t *testing.T) (repository.Repository, *gorm.DB) {
+		db, err := database.Initialize(config.Database{Driver: "sqlite", Path: t.TempDir() + "/tmf632.db"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return openGorm(t, db, false)
+	}},
+	{"gorm/postgres", func(t *testing.T) (repository.Repository, *gorm.DB) {
+		dsn := os.Getenv(postgresDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", postgresDSNEnv)
+		}
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return openGorm(t, db, true)
+	}},
+}
+
+// openGorm migrates db from scratch and returns a repository on it.
+func openGorm(t *testing.T, db *gorm.DB, rowLevelSecurity bool) (repository.Repository, *gorm.DB) {
+	ctx := context.Background()
+	m, err := database.NewMigrator(db, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _, err := m.Version(ctx); err == nil && version > 0 {
+		if err := m.Down(ctx, int(version)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.GormPlugin(rowLevelSecurity)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return repository.NewGorm(db, events.NewOutbox()), db
+}
+
+func TestConformance(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, repo repository.Repository, db *gorm.DB)
+	}{
+		{"IndividualCRUD", testIndividualCRUD},
+		{"OrganizationCRUD", testOrganizationCRUD},
+		{"Filters", testFilters},
+		{"Pagination", testPagination},
+		{"Sort", testSort},
+		{"Fields", testFields},
+		{"JSONRoundTrip", testJSONRoundTrip},
+		{"Migrations", testMigrations},
+	}
+	for _, b := range backends {
+		b := b
+		t.Run(b.name, func(t *testing.T) {
+			for _, tc := range cases {
+				tc := tc
+				t.Run(tc.name, func(t *testing.T) {
+					repo, db := b.open(t)
+					tc.run(t, repo, db)
+				})
+			}
+		})
+	}
+}
+
+func testContext() context.Context {
+	return tenant.NewContext(context.Background(), tenant.Default)
+}
+
+// individual returns a new individual with an email contact medium.
+func individual(id, givenName, familyName string) models.Individual {
+	now := time.Now().UTC().Truncate(time.Second)
+	return models.Individual{
+		ID:               id,
+		GivenName:        givenName,
+		FamilyName:       familyName,
+		CreationDate:     now,
+		ModificationDate: now,
+		Version:          1,
+		ContactMedium: []models.ContactMedium{
+			{ID: id + "-cm", MediumType: "email", EmailAddress: givenName + "@example.org"},
+		},
+	}
+}
+
+func create(t *testing.T, repo repository.Repository, individuals ...models.Individual) {
+	t.Helper()
+	for i := range individuals {
+		if err := repo.CreateIndividual(testContext(), &individuals[i]); err != nil {
+			t.Fatalf("create %s: %v", individuals[i].ID, err)
+		}
+	}
+}
+
+func testIndividualCRUD(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	ctx := testContext()
+	ann := individual("i1", "Ann", "Smith")
+	create(t, repo, ann)
+
+	duplicate := individual("i1", "Other", "Smith")
+	duplicate.ContactMedium = nil
+	if err := repo.CreateIndividual(ctx, &duplicate); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("duplicate create: got %v, want ErrDuplicate", err)
+	}
+
+	got, err := repo.GetIndividual(ctx, "i1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GivenName != "Ann" || got.Version != 1 || len(got.ContactMedium) != 1 {
+		t.Fatalf("get: %+v", got)
+	}
+
+	update := models.Individual{ID: "i1", GivenName: "Anne", Version: 2, ContactMedium: []models.ContactMedium{
+		{ID: "i1-cm", MediumType: "email", EmailAddress: "anne@example.org"},
+		{ID: "i1-cm2", MediumType: "phone", PhoneNumber: "+31612345678"},
+	}}
+	if err := repo.UpdateIndividual(ctx, &update, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateIndividual(ctx, &update, 1); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("stale update: got %v, want ErrVersionConflict", err)
+	}
+	got, err = repo.GetIndividual(ctx, "i1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GivenName != "Anne" || got.FamilyName != "Smith" || got.Version != 2 || len(got.ContactMedium) != 2 {
+		t.Fatalf("after update: %+v", got)
+	}
+
+	patched, err := repo.PatchIndividual(ctx, "i1", func(existing models.Individual) (models.Individual, error) {
+		existing.FamilyName = "Jones"
+		existing.ContactMedium = existing.ContactMedium[:1]
+		existing.Version++
+		return existing, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetIndividual(ctx, "i1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FamilyName != "Jones" || got.Version != patched.Version || len(got.ContactMedium) != 1 {
+		t.Fatalf("after patch: %+v", got)
+	}
+	if _, err := repo.PatchIndividual(ctx, "missing", func(existing models.Individual) (models.Individual, error) {
+		return existing, nil
+	}); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("patch missing: got %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteIndividual(ctx, "i1", 1); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("stale delete: got %v, want ErrVersionConflict", err)
+	}
+	if err := repo.DeleteIndividual(ctx, "i1", got.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetIndividual(ctx, "i1", query.Fields{}); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("get deleted: got %v, want ErrNotFound", err)
+	}
+}
+
+func testOrganizationCRUD(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	ctx := testContext()
+	acme := models.Organization{ID: "o1", Name: "Acme", IsLegalEntity: true, ContactMedium: []models.OrganizationContactMedium{
+		{ID: "o1-cm", MediumType: "email", EmailAddress: "info@acme.example"},
+	}}
+	if err := repo.CreateOrganization(ctx, &acme); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateOrganization(ctx, &models.Organization{ID: "o1", Name: "Other"}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("duplicate create: got %v, want ErrDuplicate", err)
+	}
+
+	if err := repo.UpdateOrganization(ctx, &models.Organization{ID: "o1", TradingName: "Acme Trading"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetOrganization(ctx, "o1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Acme" || got.TradingName != "Acme Trading" || !got.IsLegalEntity || len(got.ContactMedium) != 1 {
+		t.Fatalf("after update: %+v", got)
+	}
+
+	if err := repo.DeleteOrganization(ctx, "o1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteOrganization(ctx, "o1"); err != nil {
+		t.Fatalf("delete missing: %v", err)
+	}
+	if _, err := repo.GetOrganization(ctx, "o1", query.Fields{}); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("get deleted: got %v, want ErrNotFound", err)
+	}
+}
+
+func testFilters(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	john := individual("i1", "John", "Smith")
+	john.Nationality = "NL"
+	jane := individual("i2", "Jane", "Jones")
+	jane.ContactMedium[0] = models.ContactMedium{ID: "i2-cm", MediumType: "phone", PhoneNumber: "+31612345678"}
+	bob := individual("i3", "Bob", "Smith")
+	bob.Nationality = "TH"
+	bob.CreationDate = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	create(t, repo, john, jane, bob)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"familyName=Smith", []string{"i1", "i3"}},
+		{"familyName=Smith,Jones", []string{"i1", "i2", "i3"}},
+		{"familyName.ne=Smith", []string{"i2"}},
+		{"nationality.in=NL,TH", []string{"i1", "i3"}},
+		{"givenName.regex=^J", []string{"i1", "i2"}},
+		{"creationDate.lt=2021-01-01", []string{"i3"}},
+		{"creationDate.gte=2021-01-01", []string{"i1", "i2"}},
+		{"contactMedium.mediumType=phone", []string{"i2"}},
+		{"contactMedium.mediumType=email&familyName=Smith", []string{"i1", "i3"}},
+		{"givenName=Nobody", nil},
+	}
+	for _, tt := range tests {
+		params, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, total, err := repo.ListIndividuals(testContext(), repository.ListOptions{
+			Filters: query.ParseFilters(params),
+			Page:    query.Page{Limit: 10},
+		})
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if got := ids(found); !reflect.DeepEqual(got, tt.want) || total != int64(len(tt.want)) {
+			t.Errorf("%s: got %v (total %d), want %v", tt.query, got, total, tt.want)
+		}
+	}
+
+	for _, q := range []string{"bogus=1", "creationDate.gt=yesterday", "contactMedium.bogus=1"} {
+		params, _ := url.ParseQuery(q)
+		_, _, err := repo.ListIndividuals(testContext(), repository.ListOptions{
+			Filters: query.ParseFilters(params),
+			Page:    query.Page{Limit: 10},
+		})
+		if !errors.Is(err, query.ErrInvalidFilter) {
+			t.Errorf("%s: got %v, want ErrInvalidFilter", q, err)
+		}
+	}
+}
+
+func testPagination(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	// Created out of order: pages follow the id
+	for i := 24; i >= 0; i-- {
+		create(t, repo, individual(fmt.Sprintf("i%02d", i), "G", "F"))
+	}
+
+	tests := []struct {
+		page  query.Page
+		first string
+		count int
+	}{
+		{query.Page{Offset: 0, Limit: 10}, "i00", 10},
+		{query.Page{Offset: 10, Limit: 10}, "i10", 10},
+		{query.Page{Offset: 20, Limit: 10}, "i20", 5},
+		{query.Page{Offset: 30, Limit: 10}, "", 0},
+	}
+	for _, tt := range tests {
+		found, total, err := repo.ListIndividuals(testContext(), repository.ListOptions{Page: tt.page})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 25 || len(found) != tt.count || (tt.count > 0 && found[0].ID != tt.first) {
+			t.Errorf("%+v: got %v (total %d)", tt.page, ids(found), total)
+		}
+	}
+}
+
+func testSort(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	create(t, repo,
+		individual("i1", "ann", "smith"),
+		individual("i2", "bob", "jones"),
+		individual("i3", "cy", "smith"),
+		individual("i4", "ann", "jones"),
+	)
+
+	tests := []struct {
+		sort string
+		page query.Page
+		want []string
+	}{
+		{"givenName", query.Page{Limit: 10}, []string{"i1", "i4", "i2", "i3"}},
+		{"-givenName", query.Page{Limit: 10}, []string{"i3", "i2", "i1", "i4"}},
+		{"familyName,-givenName", query.Page{Limit: 10}, []string{"i2", "i4", "i3", "i1"}},
+		{"-familyName,givenName", query.Page{Offset: 1, Limit: 2}, []string{"i3", "i4"}},
+	}
+	for _, tt := range tests {
+		found, total, err := repo.ListIndividuals(testContext(), repository.ListOptions{
+			Sort: query.ParseSort(url.Values{"sort": {tt.sort}}),
+			Page: tt.page,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 || !reflect.DeepEqual(ids(found), tt.want) {
+			t.Errorf("sort=%s %+v: got %v (total %d), want %v", tt.sort, tt.page, ids(found), total, tt.want)
+		}
+	}
+
+	for _, sort := range []string{"bogus", "contactMedium.mediumType"} {
+		_, _, err := repo.ListIndividuals(testContext(), repository.ListOptions{
+			Sort: query.ParseSort(url.Values{"sort": {sort}}),
+			Page: query.Page{Limit: 10},
+		})
+		if !errors.Is(err, query.ErrInvalidSort) {
+			t.Errorf("sort=%s: got %v, want ErrInvalidSort", sort, err)
+		}
+	}
+}
+
+func testFields(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	ctx := testContext()
+	ann := individual("i1", "Ann", "Smith")
+	ann.ExternalReference = []models.ExternalReference{{ID: "i1-x", Name: "crm"}}
+	create(t, repo, ann)
+
+	givenName := query.ParseFields(url.Values{"fields": {"givenName"}})
+	got, err := repo.GetIndividual(ctx, "i1", givenName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GivenName != "Ann" || len(got.ContactMedium) != 0 || len(got.ExternalReference) != 0 {
+		t.Errorf("fields=givenName: %+v", got)
+	}
+
+	contactMedium := query.ParseFields(url.Values{"fields": {"contactMedium.mediumType"}})
+	got, err = repo.GetIndividual(ctx, "i1", contactMedium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ContactMedium) != 1 || len(got.ExternalReference) != 0 {
+		t.Errorf("fields=contactMedium.mediumType: %+v", got)
+	}
+
+	// Lists load sub-resources only when they are selected
+	found, _, err := repo.ListIndividuals(ctx, repository.ListOptions{Page: query.Page{Limit: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || len(found[0].ContactMedium) != 0 {
+		t.Errorf("list without fields: %+v", found)
+	}
+	found, _, err = repo.ListIndividuals(ctx, repository.ListOptions{Page: query.Page{Limit: 10}, Fields: contactMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || len(found[0].ContactMedium) != 1 {
+		t.Errorf("list with fields=contactMedium: %+v", found)
+	}
+
+	bogus := query.ParseFields(url.Values{"fields": {"bogus"}})
+	if _, err := repo.GetIndividual(ctx, "i1", bogus); !errors.Is(err, query.ErrInvalidFields) {
+		t.Errorf("fields=bogus: got %v, want ErrInvalidFields", err)
+	}
+}
+
+func testJSONRoundTrip(t *testing.T, repo repository.Repository, _ *gorm.DB) {
+	ctx := testContext()
+	start := time.Date(2020, 2, 29, 12, 30, 0, 0, time.UTC)
+	end := start.AddDate(10, 0, 0)
+
+	ann := individual("i1", "Ann", "Smith")
+	ann.HREF = "https://example.org/individual/i1"
+	ann.Title = "Dr"
+	ann.Gender = "female"
+	ann.Nationality = "NL"
+	ann.Status = "validated"
+	ann.CreatedBy, ann.ModifiedBy = "alice", "bob"
+	ann.ContactMedium = append(ann.ContactMedium, models.ContactMedium{
+		ID: "i1-addr", MediumType: "postalAddress", Preferred: true,
+		Street1: "Keizersgracht 1", City: "Amsterdam", PostCode: "1015 AA", Country: "NL",
+	})
+	ann.ExternalReference = []models.ExternalReference{{ID: "i1-x", Name: "crm", ExternalIdentifierType: "customerId"}}
+	ann.IndividualIdentification = []models.IndividualIdentification{
+		{ID: "i1-id", IdentificationType: "passport", IdentificationId: "NX1234567", ValidFor: models.TimePeriod{StartDateTime: &start, EndDateTime: &end}},
+		{ID: "i1-id2", IdentificationType: "nationalId", IdentificationId: "123"},
+	}
+	ann.PartyCharacteristic = []models.PartyCharacteristic{{ID: "i1-pc", Name: "segment", Value: "gold", ValueType: "string"}}
+	want := jsonDocument(t, ann)
+	create(t, repo, ann)
+
+	got, err := repo.GetIndividual(ctx, "i1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := jsonDocument(t, got); !reflect.DeepEqual(have, want) {
+		gotJSON, _ := json.MarshalIndent(have, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("round trip changed the individual\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+	}
+
+	acme := models.Organization{
+		ID: "o1", Name: "Acme", TradingName: "Acme BV", IsHeadOffice: true,
+		ExistsDuring: models.TimePeriod{StartDateTime: &start},
+		OrganizationIdentification: []models.OrganizationIdentification{
+			{ID: "o1-id", IdentificationType: "kvk", IdentificationId: "123", IssuingDate: &start},
+		},
+		OrganizationParentRelationship: []models.OrganizationParentRelationship{
+			{ID: "o1-parent", RelationshipType: "subsidiary", Organization: models.OrganizationRef{ID: "o0", Name: "Acme Holding"}},
+		},
+	}
+	wantOrganization := jsonDocument(t, acme)
+	if err := repo.CreateOrganization(ctx, &acme); err != nil {
+		t.Fatal(err)
+	}
+	gotOrganization, err := repo.GetOrganization(ctx, "o1", query.Fields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := jsonDocument(t, gotOrganization); !reflect.DeepEqual(have, wantOrganization) {
+		t.Errorf("round trip changed the organization\ngot:  %v\nwant: %v", have, wantOrganization)
+	}
+}
+
+// storageKeys are the JSON attributes of the storage layer, which differ
+// between backends and are not part of the API.
+var storageKeys = []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt", "IndividualID", "OrganizationID"}
+
+// jsonDocument returns the API document of v without storage attributes,
+// with times in UTC.
+func jsonDocument(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return normalize(doc)
+}
+
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, key := range storageKeys {
+			delete(v, key)
+		}
+		for key, value := range v {
+			v[key] = normalize(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+	case string:
+		if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return ts.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return v
+}
+
+func testMigrations(t *testing.T, _ repository.Repository, db *gorm.DB) {
+	if db == nil {
+		t.Skip("no schema to migrate")
+	}
+	ctx := context.Background()
+	m, err := database.NewMigrator(db, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, dirty, err := m.Version(ctx)
+	if err != nil || dirty || version != m.Latest() {
+		t.Fatalf("version %d (dirty %v, %v), want %d", version, dirty, err, m.Latest())
+	}
+
+	// Every persisted field of the models has a column
+	for _, model := range []interface{}{
+		&models.Individual{}, &models.ContactMedium{}, &models.ExternalReference{},
+		&models.IndividualIdentification{}, &models.PartyCharacteristic{},
+		&models.Organization{}, &models.OrganizationContactMedium{}, &models.OrganizationExternalReference{},
+		&models.OrganizationIdentification{}, &models.OrganizationChildRelationship{}, &models.OrganizationParentRelationship{},
+		&models.EventSubscription{}, &models.OutboxEvent{}, &models.IdempotencyKey{}, &models.AuditEntry{},
+	} {
+		columns, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have := map[string]bool{}
+		for _, column := range columns {
+			have[column.Name()] = true
+		}
+		sch, err := query.ParseSchema(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range sch.Fields {
+			if field.DBName != "" && !have[field.DBName] {
+				t.Errorf("%s has no column %s", sch.Table, field.DBName)
+			}
+		}
+	}
+
+	// The down scripts undo the up scripts
+	if err := m.Down(ctx, int(version)); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasTable(&models.Individual{}) {
+		t.Error("individuals survived migrating down")
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if version, _, _ := m.Version(ctx); version != m.Latest() {
+		t.Fatalf("version %d after migrating up again, want %d", version, m.Latest())
+	}
+}
+
+func ids(individuals []models.Individual) []string {
+	var ids []string
+	for _, individual := range individuals {
+		ids = append(ids, individual.ID)
+	}
+	return ids
+}
@@ -0,0 +1,410 @@
+// internal/repository/gorm.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/audit"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var tracer = otel.Tracer("github.com/your-username/tmf632-service/internal/repository")
+
+// association is a has-many sub-resource of a party, by field name and
+// model, in the order they are replaced and deleted.
+type association struct {
+	Name  string
+	Model interface{}
+}
+
+var individualAssociations = []association{
+	{"ContactMedium", &models.ContactMedium{}},
+	{"ExternalReference", &models.ExternalReference{}},
+	{"IndividualIdentification", &models.IndividualIdentification{}},
+	{"PartyCharacteristic", &models.PartyCharacteristic{}},
+}
+
+var organizationAssociations = []association{
+	{"ContactMedium", &models.OrganizationContactMedium{}},
+	{"ExternalReference", &models.OrganizationExternalReference{}},
+	{"OrganizationIdentification", &models.OrganizationIdentification{}},
+	{"OrganizationChildRelationship", &models.OrganizationChildRelationship{}},
+	{"OrganizationParentRelationship", &models.OrganizationParentRelationship{}},
+}
+
+// individualReadOnlyColumns are left untouched when a patched Individual is
+// written back.
+var individualReadOnlyColumns = []string{
+	"id", "href", "creation_date", "created_by", "created_at", "deleted_at",
+}
+
+// Gorm is the Repository backed by a SQL database. Events are recorded
+// through publisher, which shares the caller's transaction.
+type Gorm struct {
+	db        *gorm.DB
+	publisher events.Publisher
+}
+
+func NewGorm(db *gorm.DB, publisher events.Publisher) *Gorm {
+	return &Gorm{db: db, publisher: publisher}
+}
+
+func (r *Gorm) Transaction(ctx context.Context, fn func(tx PartyRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Gorm{db: tx, publisher: r.publisher})
+	})
+}
+
+func (r *Gorm) CreateIndividual(ctx context.Context, individual *models.Individual) error {
+	return translate(r.db.WithContext(ctx).Create(individual).Error)
+}
+
+func (r *Gorm) GetIndividual(ctx context.Context, id string, fields query.Fields) (models.Individual, error) {
+	var individual models.Individual
+	db, err := fields.Apply(r.db.WithContext(ctx), &individual, true)
+	if err != nil {
+		return individual, err
+	}
+	err = db.First(&individual, "id = ?", id).Error
+	return individual, translate(err)
+}
+
+func (r *Gorm) UpdateIndividual(ctx context.Context, individual *models.Individual, version int64) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Sub-resources are replaced below; GORM's own association upsert
+		// would adopt rows of other parties
+		result := tx.Model(&models.Individual{ID: individual.ID}).
+			Where("version = ?", version).
+			Omit(clause.Associations).
+			Updates(individual)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return replaceAssociations(tx, individual, individualAssociations)
+	}))
+}
+
+func (r *Gorm) PatchIndividual(ctx context.Context, id string, apply func(existing models.Individual) (models.Individual, error)) (models.Individual, error) {
+	var patched models.Individual
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.Individual
+		q := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		for _, assoc := range individualAssociations {
+			q = q.Preload(assoc.Name)
+		}
+		if err := q.First(&existing, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		var err error
+		if patched, err = apply(existing); err != nil {
+			return err
+		}
+		patched.ID = existing.ID
+
+		// Select("*") writes zero values too, so a patch can clear a field.
+		if err := tx.Model(&existing).
+			Select("*").
+			Omit(append(individualReadOnlyColumns, clause.Associations)...).
+			Updates(&patched).Error; err != nil {
+			return err
+		}
+
+		for _, assoc := range individualAssociations {
+			if err := replaceSubResources(tx, &patched, assoc.Name); err != nil {
+				return fmt.Errorf("replace %s: %w", assoc.Name, err)
+			}
+		}
+		return nil
+	})
+	return patched, translate(err)
+}
+
+func (r *Gorm) DeleteIndividual(ctx context.Context, id string, version int64) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteAssociations(tx, "individual_id", id, individualAssociations); err != nil {
+			return err
+		}
+
+		result := tx.Where("version = ?", version).Delete(&models.Individual{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	}))
+}
+
+func (r *Gorm) ListIndividuals(ctx context.Context, opts ListOptions) ([]models.Individual, int64, error) {
+	return list[models.Individual](r.db.WithContext(ctx), opts)
+}
+
+func (r *Gorm) CreateOrganization(ctx context.Context, organization *models.Organization) error {
+	return translate(r.db.WithContext(ctx).Create(organization).Error)
+}
+
+func (r *Gorm) GetOrganization(ctx context.Context, id string, fields query.Fields) (models.Organization, error) {
+	var organization models.Organization
+	db, err := fields.Apply(r.db.WithContext(ctx), &organization, true)
+	if err != nil {
+		return organization, err
+	}
+	err = db.First(&organization, "id = ?", id).Error
+	return organization, translate(err)
+}
+
+func (r *Gorm) UpdateOrganization(ctx context.Context, organization *models.Organization) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Organization{ID: organization.ID}).Omit(clause.Associations).Updates(organization)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return replaceAssociations(tx, organization, organizationAssociations)
+	}))
+}
+
+func (r *Gorm) DeleteOrganization(ctx context.Context, id string) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteAssociations(tx, "organization_id", id, organizationAssociations); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Organization{}, "id = ?", id).Error
+	}))
+}
+
+func (r *Gorm) ListOrganizations(ctx context.Context, opts ListOptions) ([]models.Organization, int64, error) {
+	return list[models.Organization](r.db.WithContext(ctx), opts)
+}
+
+func (r *Gorm) Publish(ctx context.Context, evt events.Event) error {
+	if r.publisher == nil {
+		return nil
+	}
+	return r.publisher.Publish(r.db.WithContext(ctx), evt)
+}
+
+func (r *Gorm) ClaimIdempotencyKey(ctx context.Context, scope, key, hash string, ttl time.Duration) (*idempotency.Response, error) {
+	return idempotency.Claim(r.db.WithContext(ctx), scope, key, hash, ttl)
+}
+
+func (r *Gorm) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp idempotency.Response) error {
+	return idempotency.Complete(r.db.WithContext(ctx), scope, key, resp)
+}
+
+func (r *Gorm) Audit(ctx context.Context, entry *models.AuditEntry) error {
+	return audit.Append(r.db.WithContext(ctx), entry)
+}
+
+func (r *Gorm) AuditTrail(ctx context.Context, resourceType, id string) ([]models.AuditEntry, error) {
+	var trail []models.AuditEntry
+	err := r.db.WithContext(ctx).
+		Where("resource_type = ? AND resource_id = ?", resourceType, id).
+		Order("sequence").
+		Find(&trail).Error
+	return trail, err
+}
+
+func (r *Gorm) CreateSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	return translate(r.db.WithContext(ctx).Create(subscription).Error)
+}
+
+func (r *Gorm) DeleteSubscription(ctx context.Context, id, owner string) error {
+	result := r.db.WithContext(ctx).Delete(&models.EventSubscription{}, "id = ? AND owner = ?", id, owner)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Gorm) ListSubscriptions(ctx context.Context) ([]models.EventSubscription, error) {
+	var subscriptions []models.EventSubscription
+	err := r.db.WithContext(ctx).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *Gorm) Stats(ctx context.Context) (Stats, error) {
+	// The counts are service-wide, over every tenant
+	var stats Stats
+	db := r.db.WithContext(tenant.System(ctx))
+	for _, count := range []struct {
+		model interface{}
+		n     *int64
+	}{
+		{&models.Individual{}, &stats.Individuals},
+		{&models.Organization{}, &stats.Organizations},
+		{&models.EventSubscription{}, &stats.Subscriptions},
+	} {
+		if err := db.Model(count.model).Count(count.n).Error; err != nil {
+			return Stats{}, err
+		}
+	}
+	return stats, nil
+}
+
+// translate maps GORM errors onto the repository's own.
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	}
+	return err
+}
+
+// list returns the page of T selected by opts and the number of rows that
+// match its filters.
+func list[T any](db *gorm.DB, opts ListOptions) ([]T, int64, error) {
+	var model T
+	db, err := query.ApplyFilters(db.Model(&model), &model, opts.Filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	selected, err := opts.Fields.Apply(db.Session(&gorm.Session{}), &model, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if selected, err = opts.Sort.Apply(selected, &model); err != nil {
+		return nil, 0, err
+	}
+
+	var items []T
+	if err := opts.Page.Apply(selected).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// replaceAssociations replaces each sub-resource list owner carries. Empty
+// lists are left as stored.
+func replaceAssociations(tx *gorm.DB, owner interface{}, associations []association) error {
+	ownerValue := reflect.Indirect(reflect.ValueOf(owner))
+	for _, assoc := range associations {
+		children := ownerValue.FieldByName(assoc.Name)
+		if children.Len() == 0 {
+			continue
+		}
+		if err := replaceAssociation(tx, owner, assoc.Name, children.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceAssociation replaces one sub-resource list in a span of its own,
+// grouping the statements GORM issues for it.
+func replaceAssociation(tx *gorm.DB, owner interface{}, name string, children interface{}) error {
+	ctx, span := tracer.Start(tx.Statement.Context, "replace "+name,
+		trace.WithAttributes(attribute.Int("association.size", reflect.ValueOf(children).Len())))
+	defer span.End()
+
+	if err := replaceSubResources(tx.WithContext(ctx), owner, name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("replace %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteAssociations deletes the sub-resources whose foreignKey column
+// holds id.
+func deleteAssociations(tx *gorm.DB, foreignKey, id string, associations []association) error {
+	for _, assoc := range associations {
+		if err := tx.Where(foreignKey+" = ?", id).Delete(assoc.Model).Error; err != nil {
+			return fmt.Errorf("delete %s: %w", assoc.Name, err)
+		}
+	}
+	return nil
+}
+
+// replaceSubResources makes the stored rows of the has-many association
+// name exactly match the slice held by owner: entries are upserted in full
+// and rows no longer present are deleted. GORM's Association.Replace only
+// rewrites the foreign key of existing rows and orphans removed ones. An
+// entry whose id belongs to another owner's row fails with ErrDuplicate
+// rather than taking that row over.
+func replaceSubResources(tx *gorm.DB, owner interface{}, name string) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(owner); err != nil {
+		return err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[name]
+	if !ok || len(rel.References) != 1 {
+		return fmt.Errorf("%s is not a has-many sub-resource", name)
+	}
+	ref := rel.References[0]
+
+	ctx := tx.Statement.Context
+	ownerValue := reflect.Indirect(reflect.ValueOf(owner))
+	ownerID, _ := ref.PrimaryKey.ValueOf(ctx, ownerValue)
+	children := rel.Field.ReflectValueOf(ctx, ownerValue)
+
+	ids := make([]interface{}, 0, children.Len())
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		if err := ref.ForeignKey.Set(ctx, child, ownerID); err != nil {
+			return err
+		}
+		childID, _ := rel.FieldSchema.PrioritizedPrimaryField.ValueOf(ctx, child)
+		ids = append(ids, childID)
+	}
+
+	stale := tx.Where(clause.Eq{Column: clause.Column{Name: ref.ForeignKey.DBName}, Value: ownerID})
+	if len(ids) > 0 {
+		stale = stale.Where(clause.Not(clause.IN{Column: clause.PrimaryColumn, Values: ids}))
+	}
+	if err := stale.Delete(reflect.New(rel.FieldSchema.ModelType).Interface()).Error; err != nil {
+		return err
+	}
+
+	if children.Len() == 0 {
+		return nil
+	}
+	// Only rows of this owner are updated on conflict; a row of another
+	// owner is left alone, so fewer rows are affected than written
+	result := tx.Clauses(clause.OnConflict{
+		UpdateAll: true,
+		Where: clause.Where{Exprs: []clause.Expression{clause.Eq{
+			Column: clause.Column{Table: rel.FieldSchema.Table, Name: ref.ForeignKey.DBName},
+			Value:  ownerID,
+		}}},
+	}).Create(children.Addr().Interface())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < int64(children.Len()) {
+		return fmt.Errorf("%w: %s id of another party", ErrDuplicate, name)
+	}
+	return nil
+}
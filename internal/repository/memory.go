@@ -0,0 +1,604 @@
+// internal/repository/memory.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/audit"
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	_ Repository = (*Gorm)(nil)
+	_ Repository = (*Memory)(nil)
+)
+
+// Memory is the Repository that keeps everything in process memory, for CI
+// and local demos. A transaction holds the write lock for its whole
+// duration and is rolled back by restoring a snapshot of the store, so it
+// is simple rather than fast. Events are handed to notify once their
+// transaction commits.
+type Memory struct {
+	mu     *sync.RWMutex
+	store  *memoryStore
+	notify func(context.Context, events.Event)
+
+	// inTx is set on the repository passed to a Transaction callback,
+	// which already holds the write lock; pending collects its events.
+	inTx    bool
+	pending []pendingEvent
+}
+
+// pendingEvent is an event published inside a transaction, with the
+// context it was published from.
+type pendingEvent struct {
+	ctx context.Context
+	evt events.Event
+}
+
+type memoryStore struct {
+	individuals   map[string]models.Individual
+	organizations map[string]models.Organization
+	subscriptions map[string]models.EventSubscription
+	idempotency   map[idempotencyID]idempotencyEntry
+	audit         map[auditID][]models.AuditEntry
+}
+
+type auditID struct {
+	resourceType, id string
+}
+
+type idempotencyID struct {
+	scope, key string
+}
+
+type idempotencyEntry struct {
+	hash     string
+	response *idempotency.Response
+	expires  time.Time
+}
+
+// NewMemory returns an empty in-memory repository. notify may be nil, in
+// which case events are discarded.
+func NewMemory(notify func(context.Context, events.Event)) *Memory {
+	return &Memory{
+		mu: &sync.RWMutex{},
+		store: &memoryStore{
+			individuals:   map[string]models.Individual{},
+			organizations: map[string]models.Organization{},
+			subscriptions: map[string]models.EventSubscription{},
+			idempotency:   map[idempotencyID]idempotencyEntry{},
+			audit:         map[auditID][]models.AuditEntry{},
+		},
+		notify: notify,
+	}
+}
+
+func (r *Memory) Transaction(ctx context.Context, fn func(tx PartyRepository) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	tx := &Memory{mu: r.mu, store: r.store, notify: r.notify, inTx: true}
+	if err := tx.run(fn); err != nil {
+		return err
+	}
+	for _, pending := range tx.pending {
+		r.deliver(pending.ctx, pending.evt)
+	}
+	return nil
+}
+
+// run calls fn holding the write lock, and restores the store if fn fails
+// or panics.
+func (tx *Memory) run(fn func(tx PartyRepository) error) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	snapshot := memoryStore{
+		individuals:   maps.Clone(tx.store.individuals),
+		organizations: maps.Clone(tx.store.organizations),
+		subscriptions: maps.Clone(tx.store.subscriptions),
+		idempotency:   maps.Clone(tx.store.idempotency),
+		audit:         maps.Clone(tx.store.audit),
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			*tx.store = snapshot
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// read and write run fn with the store locked, unless the repository is
+// already inside a transaction. Every write fn must leave the store
+// unchanged when it fails.
+func (r *Memory) read(fn func(s *memoryStore) error) error {
+	if !r.inTx {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+	}
+	return fn(r.store)
+}
+
+func (r *Memory) write(fn func(s *memoryStore) error) error {
+	if !r.inTx {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	return fn(r.store)
+}
+
+func (r *Memory) CreateIndividual(ctx context.Context, individual *models.Individual) error {
+	return r.write(func(s *memoryStore) error {
+		if _, ok := s.individuals[individual.ID]; ok {
+			return fmt.Errorf("%w: individual %s", ErrDuplicate, individual.ID)
+		}
+		if err := touch(individual, time.Now()); err != nil {
+			return err
+		}
+		s.individuals[individual.ID] = clone(*individual)
+		return nil
+	})
+}
+
+func (r *Memory) GetIndividual(ctx context.Context, id string, fields query.Fields) (models.Individual, error) {
+	var individual models.Individual
+	if err := fields.Validate(&individual); err != nil {
+		return individual, err
+	}
+	err := r.read(func(s *memoryStore) error {
+		stored, ok := s.individuals[id]
+		if !ok {
+			return ErrNotFound
+		}
+		individual = clone(stored)
+		if fields.All() {
+			return nil
+		}
+		return dropUnselected(&individual, fields)
+	})
+	return individual, err
+}
+
+func (r *Memory) UpdateIndividual(ctx context.Context, individual *models.Individual, version int64) error {
+	return r.write(func(s *memoryStore) error {
+		stored, ok := s.individuals[individual.ID]
+		if !ok || stored.Version != version {
+			return ErrVersionConflict
+		}
+		updated := clone(stored)
+		if err := merge(&updated, individual); err != nil {
+			return err
+		}
+		if err := touch(&updated, time.Now()); err != nil {
+			return err
+		}
+		s.individuals[individual.ID] = clone(updated)
+		return nil
+	})
+}
+
+func (r *Memory) PatchIndividual(ctx context.Context, id string, apply func(existing models.Individual) (models.Individual, error)) (models.Individual, error) {
+	var patched models.Individual
+	err := r.write(func(s *memoryStore) error {
+		stored, ok := s.individuals[id]
+		if !ok {
+			return ErrNotFound
+		}
+		result, err := apply(clone(stored))
+		if err != nil {
+			return err
+		}
+
+		// Identity and creation attributes are read-only
+		result.ID = stored.ID
+		result.HREF = stored.HREF
+		result.CreationDate = stored.CreationDate
+		result.CreatedBy = stored.CreatedBy
+		result.CreatedAt = stored.CreatedAt
+		result.DeletedAt = stored.DeletedAt
+		if err := touch(&result, time.Now()); err != nil {
+			return err
+		}
+
+		s.individuals[id] = clone(result)
+		patched = result
+		return nil
+	})
+	return patched, err
+}
+
+func (r *Memory) DeleteIndividual(ctx context.Context, id string, version int64) error {
+	return r.write(func(s *memoryStore) error {
+		stored, ok := s.individuals[id]
+		if !ok || stored.Version != version {
+			return ErrVersionConflict
+		}
+		delete(s.individuals, id)
+		return nil
+	})
+}
+
+func (r *Memory) ListIndividuals(ctx context.Context, opts ListOptions) ([]models.Individual, int64, error) {
+	var items []models.Individual
+	var total int64
+	err := r.read(func(s *memoryStore) error {
+		var err error
+		items, total, err = listMemory(s.individuals, opts)
+		return err
+	})
+	return items, total, err
+}
+
+func (r *Memory) CreateOrganization(ctx context.Context, organization *models.Organization) error {
+	return r.write(func(s *memoryStore) error {
+		if _, ok := s.organizations[organization.ID]; ok {
+			return fmt.Errorf("%w: organization %s", ErrDuplicate, organization.ID)
+		}
+		if err := touch(organization, time.Now()); err != nil {
+			return err
+		}
+		s.organizations[organization.ID] = clone(*organization)
+		return nil
+	})
+}
+
+func (r *Memory) GetOrganization(ctx context.Context, id string, fields query.Fields) (models.Organization, error) {
+	var organization models.Organization
+	if err := fields.Validate(&organization); err != nil {
+		return organization, err
+	}
+	err := r.read(func(s *memoryStore) error {
+		stored, ok := s.organizations[id]
+		if !ok {
+			return ErrNotFound
+		}
+		organization = clone(stored)
+		if fields.All() {
+			return nil
+		}
+		return dropUnselected(&organization, fields)
+	})
+	return organization, err
+}
+
+func (r *Memory) UpdateOrganization(ctx context.Context, organization *models.Organization) error {
+	return r.write(func(s *memoryStore) error {
+		stored, ok := s.organizations[organization.ID]
+		if !ok {
+			return ErrNotFound
+		}
+		updated := clone(stored)
+		if err := merge(&updated, organization); err != nil {
+			return err
+		}
+		if err := touch(&updated, time.Now()); err != nil {
+			return err
+		}
+		s.organizations[organization.ID] = clone(updated)
+		return nil
+	})
+}
+
+func (r *Memory) DeleteOrganization(ctx context.Context, id string) error {
+	return r.write(func(s *memoryStore) error {
+		delete(s.organizations, id)
+		return nil
+	})
+}
+
+func (r *Memory) ListOrganizations(ctx context.Context, opts ListOptions) ([]models.Organization, int64, error) {
+	var items []models.Organization
+	var total int64
+	err := r.read(func(s *memoryStore) error {
+		var err error
+		items, total, err = listMemory(s.organizations, opts)
+		return err
+	})
+	return items, total, err
+}
+
+func (r *Memory) Publish(ctx context.Context, evt events.Event) error {
+	if r.inTx {
+		r.pending = append(r.pending, pendingEvent{ctx: ctx, evt: evt})
+		return nil
+	}
+	r.deliver(ctx, evt)
+	return nil
+}
+
+func (r *Memory) deliver(ctx context.Context, evt events.Event) {
+	if r.notify != nil {
+		r.notify(ctx, evt)
+	}
+}
+
+func (r *Memory) ClaimIdempotencyKey(ctx context.Context, scope, key, hash string, ttl time.Duration) (*idempotency.Response, error) {
+	var stored *idempotency.Response
+	err := r.write(func(s *memoryStore) error {
+		// Expired keys are purged here, as there is no janitor
+		now := time.Now()
+		for id, entry := range s.idempotency {
+			if !entry.expires.After(now) {
+				delete(s.idempotency, id)
+			}
+		}
+
+		id := idempotencyID{scope: scope, key: key}
+		entry, ok := s.idempotency[id]
+		if !ok {
+			s.idempotency[id] = idempotencyEntry{hash: hash, expires: now.Add(ttl)}
+			return nil
+		}
+		if entry.hash != hash {
+			return idempotency.ErrKeyReused
+		}
+		if entry.response == nil {
+			return idempotency.ErrInProgress
+		}
+		resp := *entry.response
+		stored = &resp
+		return nil
+	})
+	return stored, err
+}
+
+func (r *Memory) CompleteIdempotencyKey(ctx context.Context, scope, key string, resp idempotency.Response) error {
+	return r.write(func(s *memoryStore) error {
+		id := idempotencyID{scope: scope, key: key}
+		entry, ok := s.idempotency[id]
+		if !ok {
+			return ErrNotFound
+		}
+		entry.response = &resp
+		s.idempotency[id] = entry
+		return nil
+	})
+}
+
+func (r *Memory) Audit(ctx context.Context, entry *models.AuditEntry) error {
+	return r.write(func(s *memoryStore) error {
+		key := auditID{entry.ResourceType, entry.ResourceID}
+		trail := s.audit[key]
+		if len(trail) == 0 {
+			audit.Seal(entry, nil)
+		} else {
+			audit.Seal(entry, &trail[len(trail)-1])
+		}
+		// A new slice, so the snapshot of a rolled back transaction keeps
+		// its own
+		s.audit[key] = append(slices.Clip(trail), *entry)
+		return nil
+	})
+}
+
+func (r *Memory) AuditTrail(ctx context.Context, resourceType, id string) ([]models.AuditEntry, error) {
+	var trail []models.AuditEntry
+	err := r.read(func(s *memoryStore) error {
+		trail = slices.Clone(s.audit[auditID{resourceType, id}])
+		return nil
+	})
+	return trail, err
+}
+
+func (r *Memory) CreateSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	return r.write(func(s *memoryStore) error {
+		if _, ok := s.subscriptions[subscription.ID]; ok {
+			return fmt.Errorf("%w: subscription %s", ErrDuplicate, subscription.ID)
+		}
+		s.subscriptions[subscription.ID] = *subscription
+		return nil
+	})
+}
+
+func (r *Memory) DeleteSubscription(ctx context.Context, id, owner string) error {
+	return r.write(func(s *memoryStore) error {
+		if subscription, ok := s.subscriptions[id]; !ok || subscription.Owner != owner {
+			return ErrNotFound
+		}
+		delete(s.subscriptions, id)
+		return nil
+	})
+}
+
+func (r *Memory) ListSubscriptions(ctx context.Context) ([]models.EventSubscription, error) {
+	var subscriptions []models.EventSubscription
+	err := r.read(func(s *memoryStore) error {
+		for _, subscription := range s.subscriptions {
+			subscriptions = append(subscriptions, subscription)
+		}
+		sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+		return nil
+	})
+	return subscriptions, err
+}
+
+func (r *Memory) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	err := r.read(func(s *memoryStore) error {
+		stats = Stats{
+			Individuals:   int64(len(s.individuals)),
+			Organizations: int64(len(s.organizations)),
+			Subscriptions: int64(len(s.subscriptions)),
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// listMemory returns the page of rows selected by opts, ordered by id as
+// the SQL repository orders them, and the number that match its filters.
+// As with the SQL repository, only sub-resources named by opts.Fields are
+// returned.
+func listMemory[T any](rows map[string]T, opts ListOptions) ([]T, int64, error) {
+	var model T
+	if err := opts.Fields.Validate(&model); err != nil {
+		return nil, 0, err
+	}
+	matcher, err := query.NewMatcher(&model, opts.Filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	sorter, err := query.NewSorter(&model, opts.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]string, 0, len(rows))
+	for id, row := range rows {
+		if matcher.Match(&row) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := rows[ids[i]], rows[ids[j]]
+		return sorter.Compare(&a, &b) < 0
+	})
+
+	start := min(opts.Page.Offset, len(ids))
+	end := len(ids)
+	if opts.Page.Limit > 0 {
+		end = min(start+opts.Page.Limit, end)
+	}
+
+	items := make([]T, 0, end-start)
+	for _, id := range ids[start:end] {
+		item := clone(rows[id])
+		if err := dropUnselected(&item, opts.Fields); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, int64(len(ids)), nil
+}
+
+// clone copies a stored value so that callers never share its
+// sub-resource slices with the store.
+func clone[T any](v T) T {
+	value := reflect.ValueOf(&v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() != reflect.Slice || field.IsNil() {
+			continue
+		}
+		copied := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		reflect.Copy(copied, field)
+		field.Set(copied)
+	}
+	return v
+}
+
+// merge copies the non-zero attributes of src onto dst, as GORM's Updates
+// does with a struct, and replaces each sub-resource list src carries.
+func merge(dst, src interface{}) error {
+	sch, err := query.ParseSchema(dst)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	dstValue := reflect.Indirect(reflect.ValueOf(dst))
+	srcValue := reflect.Indirect(reflect.ValueOf(src))
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field.PrimaryKey {
+			continue
+		}
+		if v, zero := field.ValueOf(ctx, srcValue); !zero {
+			if err := field.Set(ctx, dstValue, v); err != nil {
+				return err
+			}
+		}
+	}
+	for _, rel := range sch.Relationships.HasMany {
+		if children := rel.Field.ReflectValueOf(ctx, srcValue); children.Len() > 0 {
+			rel.Field.ReflectValueOf(ctx, dstValue).Set(children)
+		}
+	}
+	return nil
+}
+
+// touch maintains what GORM would on save: CreatedAt when unset, UpdatedAt,
+// and the owner key of every sub-resource.
+func touch(model interface{}, now time.Time) error {
+	sch, err := query.ParseSchema(model)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	value := reflect.Indirect(reflect.ValueOf(model))
+
+	if err := stamp(ctx, sch.LookUpField("CreatedAt"), sch.LookUpField("UpdatedAt"), value, now); err != nil {
+		return err
+	}
+	for _, rel := range sch.Relationships.HasMany {
+		if len(rel.References) != 1 {
+			continue
+		}
+		ref := rel.References[0]
+		ownerID, _ := ref.PrimaryKey.ValueOf(ctx, value)
+		children := rel.Field.ReflectValueOf(ctx, value)
+		for i := 0; i < children.Len(); i++ {
+			child := children.Index(i)
+			if err := ref.ForeignKey.Set(ctx, child, ownerID); err != nil {
+				return err
+			}
+			if err := stamp(ctx, rel.FieldSchema.LookUpField("CreatedAt"), rel.FieldSchema.LookUpField("UpdatedAt"), child, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func stamp(ctx context.Context, createdAt, updatedAt *schema.Field, value reflect.Value, now time.Time) error {
+	if createdAt != nil {
+		if _, zero := createdAt.ValueOf(ctx, value); zero {
+			if err := createdAt.Set(ctx, value, now); err != nil {
+				return err
+			}
+		}
+	}
+	if updatedAt != nil {
+		return updatedAt.Set(ctx, value, now)
+	}
+	return nil
+}
+
+// dropUnselected clears the sub-resources a list does not load: all of
+// them with no selection, otherwise those fields does not name. Gets load
+// them all with no selection, so they only call it with one.
+func dropUnselected(item interface{}, fields query.Fields) error {
+	sch, err := query.ParseSchema(item)
+	if err != nil {
+		return err
+	}
+	value := reflect.Indirect(reflect.ValueOf(item))
+	for _, rel := range sch.Relationships.HasMany {
+		name, ok := query.JSONPath(sch.ModelType, []string{rel.Name})
+		if ok && !fields.All() && fields.Includes(name) {
+			continue
+		}
+		children := rel.Field.ReflectValueOf(context.Background(), value)
+		children.Set(reflect.Zero(children.Type()))
+	}
+	return nil
+}
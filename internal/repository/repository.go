@@ -0,0 +1,121 @@
+// internal/repository/repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/tmf632-service/internal/events"
+	"github.com/your-username/tmf632-service/internal/idempotency"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/query"
+)
+
+// Supported repository kinds.
+const (
+	// KindDatabase keeps parties in the SQL database.
+	KindDatabase = "database"
+	// KindMemory keeps parties in process memory; nothing survives a
+	// restart. Meant for CI and local demos.
+	KindMemory = "memory"
+)
+
+var (
+	// ErrNotFound is returned when the addressed resource does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a resource is created with an id that
+	// is already taken.
+	ErrDuplicate = errors.New("duplicated key")
+	// ErrVersionConflict is returned when a conditional write finds the
+	// resource missing or at a different version than expected.
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// ListOptions selects the parties returned by a List call.
+type ListOptions struct {
+	Filters []query.Filter
+	// Sort orders the parties before they are paged; ties, and lists
+	// without a sort, are ordered by id.
+	Sort query.Sort
+	Page query.Page
+	// Fields narrows the attributes loaded. With no selection
+	// sub-resources are not loaded.
+	Fields query.Fields
+}
+
+// PartyRepository stores Individuals and Organizations together with their
+// sub-resources. Every method is safe for concurrent use.
+type PartyRepository interface {
+	// Transaction runs fn against a repository whose writes, published
+	// events and idempotency records commit together, or not at all if fn
+	// returns an error.
+	Transaction(ctx context.Context, fn func(tx PartyRepository) error) error
+
+	CreateIndividual(ctx context.Context, individual *models.Individual) error
+	// GetIndividual loads an individual with the sub-resources fields
+	// selects; the zero Fields loads all of them.
+	GetIndividual(ctx context.Context, id string, fields query.Fields) (models.Individual, error)
+	// UpdateIndividual writes the non-zero attributes of individual and
+	// replaces each sub-resource list it carries, provided the stored
+	// individual is still at version.
+	UpdateIndividual(ctx context.Context, individual *models.Individual, version int64) error
+	// PatchIndividual locks the individual, passes it to apply and stores
+	// the result in full, so attributes and sub-resource entries the
+	// result omits are cleared. Identity and creation attributes are kept.
+	PatchIndividual(ctx context.Context, id string, apply func(existing models.Individual) (models.Individual, error)) (models.Individual, error)
+	// DeleteIndividual deletes an individual and its sub-resources,
+	// provided it is still at version.
+	DeleteIndividual(ctx context.Context, id string, version int64) error
+	// ListIndividuals returns a page of matching individuals and the
+	// total number that match.
+	ListIndividuals(ctx context.Context, opts ListOptions) ([]models.Individual, int64, error)
+
+	CreateOrganization(ctx context.Context, organization *models.Organization) error
+	GetOrganization(ctx context.Context, id string, fields query.Fields) (models.Organization, error)
+	// UpdateOrganization writes the non-zero attributes of organization
+	// and replaces each sub-resource list it carries.
+	UpdateOrganization(ctx context.Context, organization *models.Organization) error
+	// DeleteOrganization deletes an organization and its sub-resources.
+	// Deleting a missing organization is not an error.
+	DeleteOrganization(ctx context.Context, id string) error
+	ListOrganizations(ctx context.Context, opts ListOptions) ([]models.Organization, int64, error)
+
+	// Publish records evt with the writes of the current transaction.
+	Publish(ctx context.Context, evt events.Event) error
+	// ClaimIdempotencyKey and CompleteIdempotencyKey behave as
+	// idempotency.Claim and idempotency.Complete.
+	ClaimIdempotencyKey(ctx context.Context, scope, key, hash string, ttl time.Duration) (*idempotency.Response, error)
+	CompleteIdempotencyKey(ctx context.Context, scope, key string, resp idempotency.Response) error
+
+	// Audit appends entry to the audit trail of its party with the writes
+	// of the current transaction, sealing it as audit.Append does.
+	Audit(ctx context.Context, entry *models.AuditEntry) error
+	// AuditTrail returns every audit entry of a party, deleted or not, in
+	// sequence order.
+	AuditTrail(ctx context.Context, resourceType, id string) ([]models.AuditEntry, error)
+}
+
+// SubscriptionRepository stores hub registrations.
+type SubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.EventSubscription) error
+	// DeleteSubscription returns ErrNotFound if owner has no subscription
+	// with id.
+	DeleteSubscription(ctx context.Context, id, owner string) error
+	ListSubscriptions(ctx context.Context) ([]models.EventSubscription, error)
+}
+
+// Stats counts what the repository stores, for monitoring.
+type Stats struct {
+	Individuals   int64
+	Organizations int64
+	Subscriptions int64
+}
+
+// Repository is implemented by each storage backend.
+type Repository interface {
+	PartyRepository
+	SubscriptionRepository
+
+	Stats(ctx context.Context) (Stats, error)
+}
@@ -0,0 +1,206 @@
+// internal/tenant/gorm.go
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// column holds the owning tenant in every table of tenant data.
+const column = "tenant_id"
+
+const startedKey = "tenant:started_transaction"
+
+// ErrNoTransaction is returned, with row-level security, for Row and Rows
+// run outside a transaction, which would find no rows rather than the
+// tenant's.
+var ErrNoTransaction = errors.New("row-level security needs a transaction for Row and Rows")
+
+// GormPlugin returns a GORM plugin confining every statement on a model
+// with a TenantID field to the tenant in the statement's context: queries,
+// updates and deletes are filtered on tenant_id, new rows are assigned to
+// the tenant and updates never move a row to another one. A statement
+// whose context has no tenant fails with ErrMissing, so a repository
+// method that forgets a condition, or loses its context, still cannot
+// cross tenants.
+//
+// With rowLevelSecurity each statement also sets app.tenant_id for the
+// transaction it runs in, starting one for queries and raw statements,
+// which the Postgres row-level security policies check as a second line
+// of defence. Row and Rows are read after the statement returns, so they
+// must run in a transaction of the caller's.
+func GormPlugin(rowLevelSecurity bool) gorm.Plugin {
+	return gormPlugin{rowLevelSecurity: rowLevelSecurity}
+}
+
+type gormPlugin struct {
+	rowLevelSecurity bool
+}
+
+func (gormPlugin) Name() string {
+	return "tenant"
+}
+
+func (p gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	errs := []error{
+		cb.Create().Before("gorm:create").Register("tenant:assign", assign),
+		cb.Query().Before("gorm:query").Register("tenant:scope_query", scope),
+		cb.Update().Before("gorm:update").Register("tenant:scope_update", scopeUpdate),
+		cb.Delete().Before("gorm:delete").Register("tenant:scope_delete", scope),
+		cb.Row().Before("gorm:row").Register("tenant:scope_row", scope),
+	}
+	if p.rowLevelSecurity {
+		errs = append(errs,
+			cb.Query().Before("gorm:query").Register("tenant:begin_transaction", begin),
+			cb.Query().After("tenant:begin_transaction").Before("gorm:query").Register("tenant:set_config", setConfig),
+			cb.Query().After("gorm:after_query").Register("tenant:commit_or_rollback_transaction", commitOrRollback),
+			cb.Create().After("gorm:begin_transaction").Before("gorm:create").Register("tenant:set_config", setConfig),
+			cb.Update().After("gorm:begin_transaction").Before("gorm:update").Register("tenant:set_config", setConfig),
+			cb.Delete().After("gorm:begin_transaction").Before("gorm:delete").Register("tenant:set_config", setConfig),
+			cb.Row().Before("gorm:row").Register("tenant:set_config", setRowConfig),
+			cb.Raw().Before("gorm:raw").Register("tenant:begin_transaction", begin),
+			cb.Raw().After("tenant:begin_transaction").Before("gorm:raw").Register("tenant:set_config", setConfig),
+			cb.Raw().After("gorm:raw").Register("tenant:commit_or_rollback_transaction", commitOrRollback),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// holdsTenantData reports whether the statement's model has a TenantID.
+func holdsTenantData(stmt *gorm.Statement) bool {
+	return stmt.Schema != nil && stmt.Schema.LookUpField(column) != nil
+}
+
+// current returns the tenant a statement on tenant data runs for, and
+// whether it is system work seeing every tenant. It records ErrMissing on
+// db when there is neither.
+func current(db *gorm.DB) (id string, system, ok bool) {
+	ctx := db.Statement.Context
+	if isSystem(ctx) {
+		return "", true, true
+	}
+	if id, ok = FromContext(ctx); !ok {
+		db.AddError(fmt.Errorf("%w: %s", ErrMissing, db.Statement.Table))
+	}
+	return id, false, ok
+}
+
+func scope(db *gorm.DB) {
+	if db.Error != nil || !holdsTenantData(db.Statement) {
+		return
+	}
+	id, system, ok := current(db)
+	if !ok || system {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: id},
+	}})
+}
+
+func scopeUpdate(db *gorm.DB) {
+	if db.Error != nil || !holdsTenantData(db.Statement) {
+		return
+	}
+	// Updates written from a whole struct, such as a patched resource,
+	// must not carry its tenant along
+	db.Statement.Omits = append(db.Statement.Omits, column)
+	scope(db)
+}
+
+// assign sets the tenant of every row being created, whatever the caller
+// put there. System work creates rows for the tenant they carry.
+func assign(db *gorm.DB) {
+	if db.Error != nil || !holdsTenantData(db.Statement) {
+		return
+	}
+	id, system, ok := current(db)
+	if !ok || system {
+		return
+	}
+
+	stmt := db.Statement
+	field := stmt.Schema.LookUpField(column)
+	set := func(row reflect.Value) {
+		if err := field.Set(stmt.Context, row, id); err != nil {
+			db.AddError(err)
+		}
+	}
+	switch rows := stmt.ReflectValue; rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			set(reflect.Indirect(rows.Index(i)))
+		}
+	case reflect.Struct:
+		set(rows)
+	}
+}
+
+// begin starts a transaction for a query outside one, so app.tenant_id can
+// be set for it alone.
+func begin(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		db.AddError(tx.Error)
+		return
+	}
+	db.InstanceSet(startedKey, db.Statement.ConnPool)
+	db.Statement.ConnPool = tx.Statement.ConnPool
+}
+
+// setRowConfig sets app.tenant_id for Row and Rows, whose transaction
+// cannot end before the caller has read the result.
+func setRowConfig(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		db.AddError(ErrNoTransaction)
+		return
+	}
+	setConfig(db)
+}
+
+func commitOrRollback(db *gorm.DB) {
+	pool, ok := db.InstanceGet(startedKey)
+	if !ok {
+		return
+	}
+	if db.Error == nil {
+		db.Commit()
+	} else {
+		db.Rollback()
+	}
+	db.Statement.ConnPool = pool.(gorm.ConnPool)
+}
+
+// setConfig tells the row-level security policies which tenant the
+// current transaction works for. Without a tenant nothing is set, and the
+// policies show no rows at all.
+func setConfig(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+	ctx := db.Statement.Context
+	value, ok := FromContext(ctx)
+	if isSystem(ctx) {
+		value, ok = all, true
+	}
+	if !ok {
+		return
+	}
+	if _, err := db.Statement.ConnPool.ExecContext(ctx, "SELECT set_config('app.tenant_id', $1, true)", value); err != nil {
+		db.AddError(fmt.Errorf("set tenant: %w", err))
+	}
+}
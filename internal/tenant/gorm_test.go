@@ -0,0 +1,195 @@
+// internal/tenant/gorm_test.go
+package tenant_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"github.com/your-username/tmf632-service/internal/config"
+	"github.com/your-username/tmf632-service/internal/database"
+	"github.com/your-username/tmf632-service/internal/models"
+	"github.com/your-username/tmf632-service/internal/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// configured records the tenants set_config was called with. SQLite has no
+// row-level security, so set_config stands in for the Postgres function.
+var configured struct {
+	sync.Mutex
+	tenants []string
+}
+
+func init() {
+	sqlite.MustRegisterScalarFunction("set_config", 3, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		configured.Lock()
+		defer configured.Unlock()
+		configured.tenants = append(configured.tenants, args[1].(string))
+		return args[1], nil
+	})
+}
+
+// takeConfigured returns the tenants set since the last call.
+func takeConfigured() []string {
+	configured.Lock()
+	defer configured.Unlock()
+	tenants := configured.tenants
+	configured.tenants = nil
+	return tenants
+}
+
+func newDB(t *testing.T, rowLevelSecurity bool) *gorm.DB {
+	t.Helper()
+	db, err := database.Initialize(config.Database{Driver: "sqlite", Path: t.TempDir() + "/tenant.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.GormPlugin(rowLevelSecurity)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestGormPlugin(t *testing.T) {
+	db := newDB(t, false)
+	t1 := db.WithContext(tenant.NewContext(context.Background(), "t1"))
+	t2 := db.WithContext(tenant.NewContext(context.Background(), "t2"))
+	system := db.WithContext(tenant.System(context.Background()))
+
+	// The tenant is assigned whatever the caller put there
+	ann := models.Individual{ID: "p1", TenantID: "t2", GivenName: "Ann"}
+	if err := t1.Create(&ann).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ann.TenantID != "t1" {
+		t.Errorf("created for %s, want t1", ann.TenantID)
+	}
+	if err := t2.Create(&models.Individual{ID: "p1", GivenName: "Bob"}).Error; err != nil {
+		t.Fatalf("same id in another tenant: %v", err)
+	}
+
+	var found []models.Individual
+	if err := t1.Find(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].GivenName != "Ann" {
+		t.Errorf("t1 sees %+v", found)
+	}
+
+	// Updates stay in the tenant and cannot move a row out of it
+	if err := t1.Model(&models.Individual{}).Where("id = ?", "p1").Update("given_name", "Anne").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := t1.Model(&models.Individual{}).Where("id = ?", "p1").Update("tenant_id", "t2").Error; err != nil {
+		t.Fatal(err)
+	}
+	moved := models.Individual{TenantID: "t2", GivenName: "Eve"}
+	if err := t1.Model(&models.Individual{}).Where("id = ?", "p1").Updates(&moved).Error; err != nil {
+		t.Fatal(err)
+	}
+	var owners []string
+	if err := system.Model(&models.Individual{}).Where("given_name = ?", "Eve").Pluck("tenant_id", &owners).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(owners, []string{"t1"}) {
+		t.Errorf("updated party belongs to %v, want t1", owners)
+	}
+
+	var t2Names []string
+	if err := t2.Model(&models.Individual{}).Order("given_name").Pluck("given_name", &t2Names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(t2Names, []string{"Bob"}) {
+		t.Errorf("t2 sees %v", t2Names)
+	}
+
+	// Deletes stay in the tenant
+	if err := t2.Where("id = ?", "p1").Delete(&models.Individual{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := t1.Model(&models.Individual{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("t1 has %d after t2 deleted its party: %v", count, err)
+	}
+
+	if err := system.Model(&models.Individual{}).Unscoped().Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("system sees %d: %v", count, err)
+	}
+
+	noTenant := db.WithContext(context.Background())
+	if err := noTenant.Find(&found).Error; !errors.Is(err, tenant.ErrMissing) {
+		t.Errorf("query without tenant: got %v, want ErrMissing", err)
+	}
+	if err := noTenant.Create(&models.Individual{ID: "p2"}).Error; !errors.Is(err, tenant.ErrMissing) {
+		t.Errorf("create without tenant: got %v, want ErrMissing", err)
+	}
+	if err := noTenant.Where("1 = 1").Delete(&models.Individual{}).Error; !errors.Is(err, tenant.ErrMissing) {
+		t.Errorf("delete without tenant: got %v, want ErrMissing", err)
+	}
+}
+
+func TestGormPluginRowLevelSecurity(t *testing.T) {
+	db := newDB(t, true)
+	// A transaction left open would block the only connection
+	q := db.WithContext(tenant.NewContext(context.Background(), "t1"))
+	takeConfigured()
+
+	if err := q.Create(&models.Individual{ID: "p1", GivenName: "Ann"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := takeConfigured(); !reflect.DeepEqual(got, []string{"t1"}) {
+		t.Errorf("create set %v", got)
+	}
+
+	var ids []string
+	var count int64
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"find", func() error { return q.Find(&[]models.Individual{}).Error }},
+		{"count", func() error { return q.Model(&models.Individual{}).Count(&count).Error }},
+		{"pluck", func() error { return q.Model(&models.Individual{}).Pluck("id", &ids).Error }},
+		{"update", func() error {
+			return q.Model(&models.Individual{}).Where("id = ?", "p1").Update("given_name", "Anne").Error
+		}},
+		{"exec", func() error { return q.Exec("UPDATE individuals SET given_name = given_name WHERE 1 = 0").Error }},
+		{"scan in transaction", func() error {
+			return q.Transaction(func(tx *gorm.DB) error {
+				return tx.Raw("SELECT id FROM individuals").Scan(&ids).Error
+			})
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Errorf("%s: %v", step.name, err)
+		}
+		if got := takeConfigured(); !reflect.DeepEqual(got, []string{"t1"}) {
+			t.Errorf("%s set %v", step.name, got)
+		}
+	}
+
+	if _, err := q.Model(&models.Individual{}).Select("id").Rows(); !errors.Is(err, tenant.ErrNoTransaction) {
+		t.Errorf("rows outside a transaction: got %v, want ErrNoTransaction", err)
+	}
+
+	system := db.WithContext(tenant.System(context.Background()))
+	if err := system.Model(&models.Individual{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("system count %d: %v", count, err)
+	}
+	if got := takeConfigured(); !reflect.DeepEqual(got, []string{"*"}) {
+		t.Errorf("system set %v", got)
+	}
+}
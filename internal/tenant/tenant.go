@@ -0,0 +1,58 @@
+// internal/tenant/tenant.go
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Default is the tenant of every party when multi-tenancy is disabled, and
+// of the parties stored before it was enabled.
+const Default = "default"
+
+// all is the tenant marker of background work that spans every tenant. It
+// cannot collide with a real tenant id, which must match validID.
+const all = "*"
+
+// ErrMissing is returned for a statement on tenant data whose context
+// names no tenant, so a code path that lost the tenant fails instead of
+// reading or writing across tenants.
+var ErrMissing = errors.New("no tenant in context")
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Valid reports whether id is a well-formed tenant id: up to 64 letters,
+// digits, dots, dashes and underscores.
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx scoped to tenant id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to. Background work started
+// with System has none.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	if !ok || id == all {
+		return "", false
+	}
+	return id, true
+}
+
+// System returns ctx for the service's own background work, such as event
+// delivery and clean-up, which sees the rows of every tenant. Requests
+// never run with it.
+func System(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, all)
+}
+
+func isSystem(ctx context.Context) bool {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id == all
+}
@@ -0,0 +1,34 @@
+// internal/tenant/tenant_test.go
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for _, id := range []string{"default", "t1", "acme.eu-west_2", strings.Repeat("a", 64)} {
+		if !Valid(id) {
+			t.Errorf("%q is not valid", id)
+		}
+	}
+	for _, id := range []string{"", "*", "-t1", ".t1", "t 1", "t1/x", strings.Repeat("a", 65)} {
+		if Valid(id) {
+			t.Errorf("%q is valid", id)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("background context has a tenant")
+	}
+	if id, ok := FromContext(NewContext(context.Background(), "t1")); !ok || id != "t1" {
+		t.Errorf("got %q %v, want t1", id, ok)
+	}
+	system := System(NewContext(context.Background(), "t1"))
+	if _, ok := FromContext(system); ok || !isSystem(system) {
+		t.Error("system context has a tenant")
+	}
+}
@@ -2,39 +2,177 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/your-username/tmf632-service/internal/models"
 )
 
+// Enumerations enforced on party resources, as published in the API
+// specification.
+var (
+	Genders         = []string{"M", "F", "O"}
+	MaritalStatuses = []string{"S", "M", "D", "W"}
+	MediumTypes     = []string{"email", "phone", "mobile", "fax", "postalAddress"}
+)
+
+// phoneMediumTypes are the medium types that must carry a phone number.
+var phoneMediumTypes = map[string]bool{"phone": true, "mobile": true, "fax": true}
+
+// Violation is one failed constraint. Pointer is the RFC 6901 JSON pointer
+// to the offending field in the request document.
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors reports every violation found in a document.
+type Errors []Violation
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Pointer + ": " + v.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
 type CustomValidator struct {
 	validator *validator.Validate
 }
 
 func NewValidator() *CustomValidator {
-	return &CustomValidator{validator: validator.New()}
+	v := validator.New()
+
+	// Report fields by their JSON names so violations point into the
+	// document the client sent.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("gender", oneOf(Genders))
+	v.RegisterValidation("maritalstatus", oneOf(MaritalStatuses))
+	v.RegisterValidation("mediumtype", oneOf(MediumTypes))
+
+	v.RegisterStructValidation(validateTimePeriod, models.TimePeriod{})
+	v.RegisterStructValidation(validateContactMedium, models.ContactMedium{}, models.OrganizationContactMedium{})
+
+	return &CustomValidator{validator: v}
 }
 
+// Validate checks i against its validate tags and the TMF rules registered
+// in NewValidator. It returns Errors listing every violation.
 func (cv *CustomValidator) Validate(i interface{}) error {
-	if err := cv.validator.Struct(i); err != nil {
+	err := cv.validator.Struct(i)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
 		return err
 	}
-	return nil
+
+	violations := make(Errors, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		violations = append(violations, Violation{
+			Pointer: pointer(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return violations
 }
 
-func (cv *CustomValidator) ValidateIndividual(individual *models.Individual) error {
-	// Add custom validation rules for Individual
-	if individual.GivenName == "" {
-		return fmt.Errorf("given name is required")
+func oneOf(values []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		s := fl.Field().String()
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+		return false
 	}
+}
 
-	// Validate contact medium
-	for _, cm := range individual.ContactMedium {
-		if cm.Type == "PhoneContactMedium" && cm.PhoneNumber == "" {
-			return fmt.Errorf("phone number is required for PhoneContactMedium")
+// validateTimePeriod requires a validFor-style period to end after it starts.
+func validateTimePeriod(sl validator.StructLevel) {
+	p := sl.Current().Interface().(models.TimePeriod)
+	if p.StartDateTime != nil && p.EndDateTime != nil && !p.EndDateTime.After(*p.StartDateTime) {
+		sl.ReportError(p.EndDateTime, "endDateTime", "EndDateTime", "afterstart", "")
+	}
+}
+
+// validateContactMedium requires the attribute matching the medium type.
+func validateContactMedium(sl validator.StructLevel) {
+	var typ, mediumType, phone, email string
+	switch cm := sl.Current().Interface().(type) {
+	case models.ContactMedium:
+		typ, mediumType, phone, email = cm.Type, cm.MediumType, cm.PhoneNumber, cm.EmailAddress
+	case models.OrganizationContactMedium:
+		typ, mediumType, phone, email = cm.Type, cm.MediumType, cm.PhoneNumber, cm.EmailAddress
+	}
+
+	if (phoneMediumTypes[mediumType] || typ == "PhoneContactMedium") && phone == "" {
+		sl.ReportError(phone, "phoneNumber", "PhoneNumber", "required", "")
+	}
+	if (mediumType == "email" || typ == "EmailContactMedium") && email == "" {
+		sl.ReportError(email, "emailAddress", "EmailAddress", "required", "")
+	}
+}
+
+// pointer turns a validator namespace such as
+// Individual.contactMedium[0].phoneNumber into /contactMedium/0/phoneNumber.
+func pointer(namespace string) string {
+	parts := strings.Split(namespace, ".")[1:]
+
+	var b strings.Builder
+	for _, part := range parts {
+		name, index := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 && strings.HasSuffix(part, "]") {
+			name, index = part[:i], part[i+1:len(part)-1]
+		}
+		b.WriteString("/" + escapePointer(name))
+		if index != "" {
+			b.WriteString("/" + escapePointer(index))
 		}
 	}
+	return b.String()
+}
 
-	return nil
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "e164":
+		return "must be an E.164 phone number, e.g. +66812345678"
+	case "email":
+		return "must be a valid email address"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	case "gender":
+		return "must be one of: " + strings.Join(Genders, ", ")
+	case "maritalstatus":
+		return "must be one of: " + strings.Join(MaritalStatuses, ", ")
+	case "mediumtype":
+		return "must be one of: " + strings.Join(MediumTypes, ", ")
+	case "afterstart":
+		return "must be after startDateTime"
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed %s=%s", fe.Tag(), fe.Param())
+	}
+	return "failed " + fe.Tag()
 }
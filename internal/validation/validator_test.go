@@ -0,0 +1,78 @@
+// internal/validation/validator_test.go
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/your-username/tmf632-service/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	v := NewValidator()
+	tests := []struct {
+		doc  string
+		want []string // pointer and rule of each violation
+	}{
+		{`{"givenName":"Ann","gender":"F","nationality":"TH","contactMedium":[{"mediumType":"email","emailAddress":"ann@example.com"}]}`, nil},
+		{`{}`, []string{"/givenName", "required"}},
+		{`{"givenName":"Ann","gender":"X","maritalStatus":"Q","nationality":"Thai"}`, []string{
+			"/maritalStatus", "maritalstatus",
+			"/gender", "gender",
+			"/nationality", "iso3166_1_alpha2",
+		}},
+		{`{"givenName":"Ann","contactMedium":[{"mediumType":"email"},{"mediumType":"pager"},{"mediumType":"mobile","phoneNumber":"0812345678"}]}`, []string{
+			"/contactMedium/0/emailAddress", "required",
+			"/contactMedium/1/mediumType", "mediumtype",
+			"/contactMedium/2/phoneNumber", "e164",
+		}},
+		{`{"givenName":"Ann","individualIdentification":[{"validFor":{"startDateTime":"2030-01-01T00:00:00Z","endDateTime":"2020-01-01T00:00:00Z"}}]}`, []string{
+			"/individualIdentification/0/validFor/endDateTime", "afterstart",
+		}},
+		// An open-ended period is valid
+		{`{"givenName":"Ann","individualIdentification":[{"validFor":{"startDateTime":"2030-01-01T00:00:00Z"}}]}`, nil},
+	}
+	for _, tt := range tests {
+		var individual models.Individual
+		if err := json.Unmarshal([]byte(tt.doc), &individual); err != nil {
+			t.Fatal(err)
+		}
+		err := v.Validate(&individual)
+		var violations Errors
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: %v", tt.doc, err)
+			}
+			continue
+		}
+		if !errors.As(err, &violations) {
+			t.Errorf("%s: got %v, want violations", tt.doc, err)
+			continue
+		}
+		var got []string
+		for _, v := range violations {
+			if v.Message == "" {
+				t.Errorf("%s: %s has no message", tt.doc, v.Pointer)
+			}
+			got = append(got, v.Pointer, v.Rule)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestPointer(t *testing.T) {
+	tests := map[string]string{
+		"Individual.givenName":                    "/givenName",
+		"Individual.contactMedium[0].phoneNumber": "/contactMedium/0/phoneNumber",
+		"Individual.a/b.c~d":                      "/a~1b/c~0d",
+	}
+	for namespace, want := range tests {
+		if got := pointer(namespace); got != want {
+			t.Errorf("%s: got %s, want %s", namespace, got, want)
+		}
+	}
+}